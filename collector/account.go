@@ -4,20 +4,19 @@ import (
 	"context"
 	"log"
 
-	"github.com/linode/linodego"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 // AccountCollector represents a Linode Account
 type AccountCollector struct {
-	client linodego.Client
+	client AccountGetter
 
 	Balance    *prometheus.Desc
 	Uninvoiced *prometheus.Desc
 }
 
 // NewAccountCollector creates an AccountCollector
-func NewAccountCollector(client linodego.Client) *AccountCollector {
+func NewAccountCollector(client AccountGetter) *AccountCollector {
 	log.Println("[NewAccountCollector] Entered")
 	subsystem := "account"
 	labelKeys := []string{"company", "email"}
@@ -41,8 +40,12 @@ func NewAccountCollector(client linodego.Client) *AccountCollector {
 
 // Collect implements Collector interface and is called by Prometheus to collect metrics
 func (c *AccountCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector interface and collects metrics until ctx is done
+func (c *AccountCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
 	log.Println("[AccountCollector:Collect] Entered")
-	ctx := context.Background()
 
 	account, err := c.client.GetAccount(ctx)
 	if err != nil {
@@ -50,13 +53,13 @@ func (c *AccountCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	ch <- prometheus.MustNewConstMetric(
+	ch <- newConstMetric(
 		c.Balance,
 		prometheus.GaugeValue,
 		float64(account.Balance),
 		[]string{account.Company, account.Email}...,
 	)
-	ch <- prometheus.MustNewConstMetric(
+	ch <- newConstMetric(
 		c.Uninvoiced,
 		prometheus.GaugeValue,
 		float64(account.BalanceUninvoiced),
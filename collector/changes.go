@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// changes detects resources that are created and deleted between refreshes of the Inventory
+// The first refresh of each type of resource is the baseline; its resources are not counted as created
+// changes is also a Collector that reports the counts of created and deleted resources and when resources were first seen
+type changes struct {
+	mu sync.Mutex
+	// firstSeen is the time that each resource (by type and ID) was first seen
+	firstSeen map[string]map[string]time.Time
+
+	Created   *prometheus.CounterVec
+	Deleted   *prometheus.CounterVec
+	FirstSeen *prometheus.Desc
+}
+
+func newChanges() *changes {
+	return &changes{
+		firstSeen: map[string]map[string]time.Time{},
+
+		Created: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "resources_created_total",
+				Help:      "Number of resources created (first seen by the exporter after it started) by type",
+			},
+			[]string{"type"},
+		),
+		Deleted: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "resources_deleted_total",
+				Help:      "Number of resources deleted (no longer seen by the exporter) by type",
+			},
+			[]string{"type"},
+		),
+		FirstSeen: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "resource", "first_seen_timestamp_seconds"),
+			"Time at which the exporter first saw the resource",
+			[]string{"type", "id"},
+			nil,
+		),
+	}
+}
+
+// observe compares the IDs of the resources of a type to those previously observed
+func (c *changes) observe(resourceType string, ids []string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous, ok := c.firstSeen[resourceType]
+	current := make(map[string]time.Time, len(ids))
+	for _, id := range ids {
+		if t, ok := previous[id]; ok {
+			current[id] = t
+			continue
+		}
+		current[id] = now
+		if ok {
+			log.Printf("[changes:observe] %s (%s) created", resourceType, id)
+			c.Created.WithLabelValues(resourceType).Inc()
+		}
+	}
+	if !ok {
+		// Baseline: report zero counts from the first refresh
+		c.Created.WithLabelValues(resourceType)
+		c.Deleted.WithLabelValues(resourceType)
+	}
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			log.Printf("[changes:observe] %s (%s) deleted", resourceType, id)
+			c.Deleted.WithLabelValues(resourceType).Inc()
+		}
+	}
+	c.firstSeen[resourceType] = current
+}
+
+// Collect implements Collector interface and is called by Prometheus to collect metrics
+func (c *changes) Collect(ch chan<- prometheus.Metric) {
+	c.Created.Collect(ch)
+	c.Deleted.Collect(ch)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for resourceType, resources := range c.firstSeen {
+		for id, t := range resources {
+			ch <- newConstMetric(
+				c.FirstSeen,
+				prometheus.GaugeValue,
+				float64(t.Unix()),
+				resourceType, id,
+			)
+		}
+	}
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (c *changes) Describe(ch chan<- *prometheus.Desc) {
+	c.Created.Describe(ch)
+	c.Deleted.Describe(ch)
+	ch <- c.FirstSeen
+}
+
+// resourceIDs returns the IDs of the items
+func resourceIDs[T any](items []T, id func(T) string) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = id(item)
+	}
+	return ids
+}
@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/linode/linodego"
+)
+
+//go:generate go run github.com/matryer/moq@v0.6.0 -skip-ensure -pkg mock -out ../mock/collector.go . AccountGetter InstanceLister InstanceStatsGetter VolumeLister NodeBalancerLister NodeBalancerStatsGetter LKEClusterLister LKENodePoolLister ObjectStorageBucketLister ObjectStorageClient DomainLister TagLister TicketLister UserLister
+
+// The collectors depend upon these (narrow) subsets of the Linode API client
+// *linodego.Client satisfies all of them; fakes are provided by the mock package
+
+// AccountGetter gets the Linode account
+type AccountGetter interface {
+	GetAccount(ctx context.Context) (*linodego.Account, error)
+}
+
+// InstanceLister lists Linodes
+type InstanceLister interface {
+	ListInstances(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Instance, error)
+}
+
+// InstanceStatsGetter gets a Linode's stats
+type InstanceStatsGetter interface {
+	GetInstanceStats(ctx context.Context, linodeID int) (*linodego.InstanceStats, error)
+}
+
+// VolumeLister lists Volumes
+type VolumeLister interface {
+	ListVolumes(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Volume, error)
+}
+
+// NodeBalancerLister lists NodeBalancers
+type NodeBalancerLister interface {
+	ListNodeBalancers(ctx context.Context, opts *linodego.ListOptions) ([]linodego.NodeBalancer, error)
+}
+
+// NodeBalancerStatsGetter gets a NodeBalancer's stats
+type NodeBalancerStatsGetter interface {
+	GetNodeBalancerStats(ctx context.Context, nodebalancerID int) (*linodego.NodeBalancerStats, error)
+}
+
+// LKEClusterLister lists LKE clusters
+type LKEClusterLister interface {
+	ListLKEClusters(ctx context.Context, opts *linodego.ListOptions) ([]linodego.LKECluster, error)
+}
+
+// LKENodePoolLister lists an LKE cluster's node pools
+type LKENodePoolLister interface {
+	ListLKENodePools(ctx context.Context, clusterID int, opts *linodego.ListOptions) ([]linodego.LKENodePool, error)
+}
+
+// ObjectStorageBucketLister lists Object Storage buckets
+type ObjectStorageBucketLister interface {
+	ListObjectStorageBuckets(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageBucket, error)
+}
+
+// ObjectStorageClient gets Object Storage buckets' access, endpoints, quotas, keys and transfer
+type ObjectStorageClient interface {
+	GetObjectStorageBucketAccessV2(ctx context.Context, clusterOrRegionID, label string) (*linodego.ObjectStorageBucketAccessV2, error)
+	ListObjectStorageEndpoints(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageEndpoint, error)
+	ListObjectStorageQuotas(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageQuota, error)
+	GetObjectStorageQuotaUsage(ctx context.Context, quotaID string) (*linodego.ObjectStorageQuotaUsage, error)
+	ListObjectStorageKeys(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageKey, error)
+	GetObjectStorageTransfer(ctx context.Context) (*linodego.ObjectStorageTransfer, error)
+}
+
+// DomainLister lists Domains
+type DomainLister interface {
+	ListDomains(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Domain, error)
+}
+
+// TagLister lists tags and the objects to which a tag is applied
+type TagLister interface {
+	ListTags(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Tag, error)
+	ListTaggedObjects(ctx context.Context, label string, opts *linodego.ListOptions) (linodego.TaggedObjectList, error)
+}
+
+// TicketLister lists support tickets
+type TicketLister interface {
+	ListTickets(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Ticket, error)
+}
+
+// UserLister lists the account's users
+type UserLister interface {
+	ListUsers(ctx context.Context, opts *linodego.ListOptions) ([]linodego.User, error)
+}
+
+// InventoryClient lists the resources shared by the Inventory
+type InventoryClient interface {
+	InstanceLister
+	VolumeLister
+	NodeBalancerLister
+	LKEClusterLister
+	ObjectStorageBucketLister
+	DomainLister
+}
+
+// TicketClient lists support tickets and the account's users
+type TicketClient interface {
+	TicketLister
+	UserLister
+}
+
+// Client is the subset of the Linode API client used by every collector
+type Client interface {
+	AccountGetter
+	InventoryClient
+	InstanceStatsGetter
+	NodeBalancerStatsGetter
+	LKENodePoolLister
+	ObjectStorageClient
+	TagLister
+	TicketClient
+}
+
+var (
+	_ Client                  = (*linodego.Client)(nil)
+	_ AccountGetter           = (*linodego.Client)(nil)
+	_ InventoryClient         = (*linodego.Client)(nil)
+	_ InstanceStatsGetter     = (*linodego.Client)(nil)
+	_ NodeBalancerStatsGetter = (*linodego.Client)(nil)
+	_ LKENodePoolLister       = (*linodego.Client)(nil)
+	_ ObjectStorageClient     = (*linodego.Client)(nil)
+	_ TagLister               = (*linodego.Client)(nil)
+	_ TicketClient            = (*linodego.Client)(nil)
+)
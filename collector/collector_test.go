@@ -0,0 +1,322 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/linode-exporter/linodefake"
+	"github.com/DazWilkin/linode-exporter/mock"
+	"github.com/linode/linodego"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/expfmt"
+)
+
+var update = flag.Bool("update", false, "update golden files (testdata/*.golden)")
+
+// lintExceptions are metrics whose names predate linting and are retained for compatibility
+var lintExceptions = map[string]bool{
+	"linode_exporter_info":                     true,
+	"linode_instance_up":                       true,
+	"linode_kubernetes_up":                     true,
+	"linode_kubernetes_linode_up":              true,
+	"linode_nodebalancer_transfer_in_bytes":    true,
+	"linode_nodebalancer_transfer_out_bytes":   true,
+	"linode_nodebalancer_transfer_total_bytes": true,
+	"linode_objectstorage_objects_count":       true,
+	"linode_tickets_count":                     true,
+	"linode_volume_up":                         true,
+}
+
+func TestCollectors(t *testing.T) {
+	server := linodefake.NewServer(linodefake.Fixtures())
+	defer server.Close()
+
+	client := server.Client()
+	inventory := NewInventory(client, nil, time.Minute)
+	tags := NewTagLabels([]string{"env"})
+
+	for _, test := range []struct {
+		name      string
+		collector prometheus.Collector
+		// exclude are metrics whose values depend on when the test is run
+		exclude []string
+	}{
+		{name: "account", collector: NewAccountCollector(client)},
+		{name: "domain", collector: NewDomainCollector(inventory, tags)},
+		{name: "instance", collector: NewInstanceCollector(inventory, tags)},
+		{name: "instance_without_tags", collector: NewInstanceCollector(inventory, nil)},
+		{name: "instance_stats", collector: NewInstanceStatsCollector(client, inventory, nil)},
+		{name: "kubernetes", collector: NewKubernetesCollector(client, inventory, nil, tags)},
+		{
+			name:      "nodebalancer",
+			collector: NewNodeBalancerCollector(inventory, tags),
+			exclude:   []string{"linode_nodebalancer_transfer_month_start_timestamp_seconds"},
+		},
+		{name: "nodebalancer_stats", collector: NewNodeBalancerStatsCollector(client, inventory, nil)},
+		{name: "objectstorage", collector: NewObjectStorageCollector(client, inventory, nil, nil)},
+		{name: "tag", collector: NewTagCollector(client, nil)},
+		{
+			name:      "ticket",
+			collector: NewTicketCollector(client),
+			exclude: []string{
+				"linode_tickets_oldest_open_age_seconds",
+				"linode_tickets_last_updated_age_seconds",
+			},
+		},
+		{name: "volume", collector: NewVolumeCollector(inventory, tags)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			lint(t, test.collector)
+			golden(t, filepath.Join("testdata", test.name+".golden"), test.collector, test.exclude...)
+		})
+	}
+}
+
+func TestExporterCollector(t *testing.T) {
+	// linode_exporter_info is labeled with the Go version and so is not compared to a golden file
+	c := NewExporterCollector("linux", "0123456789")
+	lint(t, c)
+	if got := testutil.CollectAndCount(c, "linode_exporter_info"); got != 1 {
+		t.Errorf("got %d linode_exporter_info metrics; want 1", got)
+	}
+}
+
+// lint fails the test if any of the collector's metrics (other than lintExceptions) have lint problems
+func lint(t *testing.T, c prometheus.Collector) {
+	t.Helper()
+	problems, err := testutil.CollectAndLint(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		if lintExceptions[p.Metric] {
+			continue
+		}
+		t.Errorf("%s: %s", p.Metric, p.Text)
+	}
+}
+
+// golden compares the collector's metrics (other than exclude) to the golden file
+// If -update is set, the golden file is (re)written instead
+func golden(t *testing.T, path string, c prometheus.Collector, exclude ...string) {
+	t.Helper()
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, mf := range mfs {
+		if slices.Contains(exclude, mf.GetName()) {
+			continue
+		}
+		names = append(names, mf.GetName())
+		if err := enc.Encode(mf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if *update {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create golden files)", err)
+	}
+
+	// Compare every metric that is either collected or expected so that missing and unexpected metrics are differences too
+	var parser expfmt.TextParser
+	expected, err := parser.TextToMetricFamilies(bytes.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range expected {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		t.Fatal("no metrics were collected or expected")
+	}
+	if err := testutil.CollectAndCompare(c, bytes.NewReader(want), names...); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInstanceCollectorWithoutSpecs(t *testing.T) {
+	client := &mock.InventoryClient{
+		InstanceListerMock: mock.InstanceListerMock{
+			ListInstancesFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Instance, error) {
+				return []linodego.Instance{
+					{ID: 1, Label: "no-specs", Region: "us-east"},
+				}, nil
+			},
+		},
+	}
+	c := NewInstanceCollector(NewInventory(client, nil, time.Minute), nil)
+
+	// Only linode_instance_up is reported for a Linode without specs
+	if got := testutil.CollectAndCount(c); got != 1 {
+		t.Errorf("got %d metrics; want 1", got)
+	}
+}
+
+func TestInstanceStatsCollectorError(t *testing.T) {
+	instances := &mock.InventoryClient{
+		InstanceListerMock: mock.InstanceListerMock{
+			ListInstancesFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Instance, error) {
+				return []linodego.Instance{
+					{ID: 1, Label: "a", Region: "us-east"},
+					{ID: 2, Label: "b", Region: "us-east"},
+				}, nil
+			},
+		},
+	}
+	stats := &mock.InstanceStatsGetterMock{
+		GetInstanceStatsFunc: func(ctx context.Context, linodeID int) (*linodego.InstanceStats, error) {
+			if linodeID == 1 {
+				return nil, errors.New("unavailable")
+			}
+			return &linodego.InstanceStats{
+				Data: linodego.InstanceStatsData{
+					CPU: [][]float64{{1700000000000, 5}},
+				},
+			}, nil
+		},
+	}
+	c := NewInstanceStatsCollector(stats, NewInventory(instances, nil, time.Minute), nil)
+
+	// Stats are reported for the Linode whose stats were retrieved (and only for the samples it has)
+	if got := testutil.CollectAndCount(c, "linode_instance_stats_cpu_usage"); got != 1 {
+		t.Errorf("got %d linode_instance_stats_cpu_usage metrics; want 1", got)
+	}
+	if got := len(stats.GetInstanceStatsCalls()); got != 2 {
+		t.Errorf("got %d GetInstanceStats calls; want 2", got)
+	}
+}
+
+func TestInventoryChanges(t *testing.T) {
+	instances := [][]linodego.Instance{
+		{{ID: 1}, {ID: 2}},
+		{{ID: 2}, {ID: 3}, {ID: 4}},
+	}
+	client := &mock.InventoryClient{
+		InstanceListerMock: mock.InstanceListerMock{
+			ListInstancesFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Instance, error) {
+				result := instances[0]
+				if len(instances) > 1 {
+					instances = instances[1:]
+				}
+				return result, nil
+			},
+		},
+	}
+	inventory := NewInventory(client, nil, 0)
+	lint(t, inventory)
+
+	// The first refresh is the baseline
+	if _, err := inventory.Instances(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.CollectAndCount(inventory, "linode_resources_created_total", "linode_resources_deleted_total"); got != 2 {
+		t.Errorf("got %d created and deleted metrics; want 2", got)
+	}
+	if got := testutil.ToFloat64(inventory.changes.Created.WithLabelValues("linode")); got != 0 {
+		t.Errorf("got %v created; want 0", got)
+	}
+
+	// Linodes 3 and 4 were created and Linode 1 was deleted
+	if _, err := inventory.Instances(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(inventory.changes.Created.WithLabelValues("linode")); got != 2 {
+		t.Errorf("got %v created; want 2", got)
+	}
+	if got := testutil.ToFloat64(inventory.changes.Deleted.WithLabelValues("linode")); got != 1 {
+		t.Errorf("got %v deleted; want 1", got)
+	}
+	if got := testutil.CollectAndCount(inventory, "linode_resource_first_seen_timestamp_seconds"); got != 3 {
+		t.Errorf("got %d first seen metrics; want 3", got)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	server := linodefake.NewServer(linodefake.Fixtures())
+	defer server.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+	if err := Register(registry, server.Client(), Options{
+		Collectors:   []string{"instance", "volume"},
+		TagLabels:    []string{"env"},
+		InventoryTTL: time.Minute,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]int{
+		"linode_instance_up":                 4,
+		"linode_volume_up":                   1,
+		"linode_domain_up":                   0,
+		"linode_exporter_collector_success":  2,
+		"linode_exporter_api_requests_total": 2,
+	} {
+		if got, err := testutil.GatherAndCount(registry, name); err != nil || got != want {
+			t.Errorf("got %d %s metrics (%v); want %d", got, name, err, want)
+		}
+	}
+
+	if err := Register(prometheus.NewRegistry(), server.Client(), Options{
+		Collectors: []string{"unknown"},
+	}); err == nil {
+		t.Error("got nil error for an unknown collector")
+	}
+}
+
+func TestCatalogue(t *testing.T) {
+	scraper, err := New(&mockClient{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, d := range Catalogue() {
+		if d.Description == "" {
+			t.Errorf("%s has no description", d.Name)
+		}
+		names = append(names, d.Name)
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("got %v; want sorted names", names)
+	}
+	if len(scraper.collectors) != len(names) {
+		t.Errorf("got %d collectors; want %d (every collector)", len(scraper.collectors), len(names))
+	}
+}
+
+// mockClient is a Client whose methods are not expected to be called
+type mockClient struct {
+	mock.AccountGetterMock
+	mock.InventoryClient
+	mock.InstanceStatsGetterMock
+	mock.NodeBalancerStatsGetterMock
+	mock.LKENodePoolListerMock
+	mock.ObjectStorageClientMock
+	mock.TagListerMock
+	mock.TicketClient
+}
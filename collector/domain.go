@@ -0,0 +1,77 @@
+package collector
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DomainCollector represents a Linode (DNS) Domain
+type DomainCollector struct {
+	inventory *Inventory
+	tags      *TagLabels
+
+	Up   *prometheus.Desc
+	Tags *prometheus.Desc
+}
+
+// NewDomainCollector creates a DomainCollector
+// tags may be nil if no tags are to be mapped onto labels
+func NewDomainCollector(inventory *Inventory, tags *TagLabels) *DomainCollector {
+	log.Println("[NewDomainCollector] Entered")
+	subsystem := "domain"
+	labelKeys := []string{"id", "domain", "type", "status"}
+	return &DomainCollector{
+		inventory: inventory,
+		tags:      tags,
+
+		Up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "up"),
+			"Status of Domain",
+			append(labelKeys, tags.Names()...),
+			nil,
+		),
+		Tags: newTagInfoDesc(subsystem),
+	}
+}
+
+// Collect implements Collector interface and is called by Prometheus to collect metrics
+func (c *DomainCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector interface and collects metrics until ctx is done
+func (c *DomainCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	log.Println("[DomainCollector:Collect] Entered")
+
+	domains, err := c.inventory.Domains(ctx)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	log.Printf("[DomainCollector:Collect] len(domains)=%d", len(domains))
+
+	for _, d := range domains {
+		labelValues := []string{
+			strconv.Itoa(d.ID), d.Domain, string(d.Type), string(d.Status),
+		}
+		ch <- newConstMetric(
+			c.Up,
+			prometheus.GaugeValue,
+			1.0,
+			append(labelValues, c.tags.Values(d.Tags)...)...,
+		)
+		collectTagInfo(ch, c.Tags, labelValues[0], d.Tags)
+	}
+	log.Println("[DomainCollector:Collect] Completes")
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (c *DomainCollector) Describe(ch chan<- *prometheus.Desc) {
+	log.Println("[DomainCollector:Describe] Entered")
+	ch <- c.Up
+	ch <- c.Tags
+	log.Println("[DomainCollector:Describe] Completes")
+}
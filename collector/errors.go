@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// reasonInvalidMetric is the reason recorded when a metric cannot be constructed
+	reasonInvalidMetric = "invalid_metric"
+	// reasonPanic is the reason recorded when a collector panics
+	reasonPanic = "panic"
+)
+
+// collectError is an invalid metric that records the reason for the error
+// The Scraper counts collectErrors by collector and reason
+type collectError struct {
+	prometheus.Metric
+	reason string
+}
+
+// newCollectError returns an invalid metric for desc that records the reason for err
+func newCollectError(desc *prometheus.Desc, reason string, err error) prometheus.Metric {
+	log.Printf("[newCollectError] %s: %v", reason, err)
+	return collectError{
+		Metric: prometheus.NewInvalidMetric(desc, err),
+		reason: reason,
+	}
+}
+
+// newConstMetric is prometheus.MustNewConstMetric except that, rather than panicking,
+// it returns an invalid metric if the metric cannot be constructed
+func newConstMetric(desc *prometheus.Desc, valueType prometheus.ValueType, value float64, labelValues ...string) prometheus.Metric {
+	m, err := prometheus.NewConstMetric(desc, valueType, value, labelValues...)
+	if err != nil {
+		return newCollectError(desc, reasonInvalidMetric, err)
+	}
+	return m
+}
+
+// newMetricWithTimestamp is prometheus.NewMetricWithTimestamp except that invalid metrics are returned unchanged
+func newMetricWithTimestamp(t time.Time, m prometheus.Metric) prometheus.Metric {
+	if _, ok := m.(collectError); ok {
+		return m
+	}
+	return prometheus.NewMetricWithTimestamp(t, m)
+}
+
+// recoverPanic recovers from a panic in a collector's goroutine and reports it as an invalid metric for desc
+// recoverPanic must be deferred directly by the goroutine, e.g. defer recoverPanic(ch, c.Up)
+func recoverPanic(ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+	if r := recover(); r != nil {
+		log.Printf("[recoverPanic] %v\n%s", r, debug.Stack())
+		ch <- newCollectError(desc, reasonPanic, fmt.Errorf("panic: %v", r))
+	}
+}
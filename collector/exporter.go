@@ -4,14 +4,11 @@ import (
 	"log"
 	"runtime"
 
-	"github.com/linode/linodego"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 // ExporterCollector represents the Linode Exporter (itself)
 type ExporterCollector struct {
-	client linodego.Client
-
 	Up *prometheus.Desc
 
 	osVersion string
@@ -19,12 +16,11 @@ type ExporterCollector struct {
 }
 
 // NewExporterCollector creates an ExporterCollector
-func NewExporterCollector(client linodego.Client, osVersion, gitCommit string) *ExporterCollector {
+func NewExporterCollector(osVersion, gitCommit string) *ExporterCollector {
 	log.Println("[NewExporterCollector] Entered")
 	subsystem := "exporter"
 	labelKeys := []string{"goVersion", "osVersion", "exporterCommit"}
 	return &ExporterCollector{
-		client: client,
 		Up: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "info"),
 			"A metric with a constant value of '1' labeled with go, OS and the exporter versions",
@@ -45,7 +41,7 @@ func NewExporterCollector(client linodego.Client, osVersion, gitCommit string) *
 // Collect implements Collector interface and is called by Prometheus to collect metrics
 func (c *ExporterCollector) Collect(ch chan<- prometheus.Metric) {
 	log.Println("[ExporterCollector:Collect] Entered")
-	ch <- prometheus.MustNewConstMetric(
+	ch <- newConstMetric(
 		c.Up,
 		prometheus.CounterValue,
 		1,
@@ -0,0 +1,152 @@
+package collector
+
+import (
+	"encoding/json"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/linode/linodego"
+)
+
+// Filter includes or excludes resources by tag, region, label (regex) and ID
+// Include criteria must all be satisfied (when set); any matching exclude criterion excludes the resource
+// Criteria that do not apply to a resource type (e.g. regions for Domains) are ignored
+// A nil *Filter includes every resource
+type Filter struct {
+	IncludeTags    []string
+	ExcludeTags    []string
+	IncludeRegions []string
+	ExcludeRegions []string
+	IncludeLabel   *regexp.Regexp
+	ExcludeLabel   *regexp.Regexp
+	IncludeIDs     []int
+	ExcludeIDs     []int
+}
+
+// listOptions returns ListOptions that push the filter's include criteria for the given fields ("region", "tags") down to the Linode API
+// Only one field is pushed down (the API filter is a pre-filter); the filter is always applied client-side too
+func (f *Filter) listOptions(fields ...string) *linodego.ListOptions {
+	if f == nil {
+		return nil
+	}
+	for _, field := range fields {
+		var values []string
+		switch field {
+		case "region":
+			values = f.IncludeRegions
+		case "tags":
+			values = f.IncludeTags
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		filter := &linodego.Filter{}
+		if len(values) > 1 {
+			filter = linodego.Or("", "")
+		}
+		for _, value := range values {
+			filter.AddField(linodego.Eq, field, value)
+		}
+		b, err := json.Marshal(filter)
+		if err != nil {
+			log.Println(err)
+			return nil
+		}
+		return linodego.NewListOptions(0, string(b))
+	}
+	return nil
+}
+
+// match returns true if the resource satisfies the filter
+// id, region and tags are nil when these do not apply to the resource type
+func (f *Filter) match(id *int, label string, region *string, tags *[]string) bool {
+	if f == nil {
+		return true
+	}
+	if id != nil {
+		if len(f.IncludeIDs) > 0 && !slices.Contains(f.IncludeIDs, *id) {
+			return false
+		}
+		if slices.Contains(f.ExcludeIDs, *id) {
+			return false
+		}
+	}
+	if region != nil {
+		if len(f.IncludeRegions) > 0 && !slices.Contains(f.IncludeRegions, *region) {
+			return false
+		}
+		if slices.Contains(f.ExcludeRegions, *region) {
+			return false
+		}
+	}
+	if tags != nil {
+		if len(f.IncludeTags) > 0 && !containsAny(*tags, f.IncludeTags) {
+			return false
+		}
+		if containsAny(*tags, f.ExcludeTags) {
+			return false
+		}
+	}
+	if f.IncludeLabel != nil && !f.IncludeLabel.MatchString(label) {
+		return false
+	}
+	if f.ExcludeLabel != nil && f.ExcludeLabel.MatchString(label) {
+		return false
+	}
+	return true
+}
+
+func (f *Filter) matchInstance(i linodego.Instance) bool {
+	return f.match(&i.ID, i.Label, &i.Region, &i.Tags)
+}
+
+func (f *Filter) matchVolume(v linodego.Volume) bool {
+	return f.match(&v.ID, v.Label, &v.Region, &v.Tags)
+}
+
+func (f *Filter) matchNodeBalancer(nb linodego.NodeBalancer) bool {
+	label := ""
+	if nb.Label != nil {
+		label = *nb.Label
+	}
+	return f.match(&nb.ID, label, &nb.Region, &nb.Tags)
+}
+
+func (f *Filter) matchLKECluster(k linodego.LKECluster) bool {
+	return f.match(&k.ID, k.Label, &k.Region, &k.Tags)
+}
+
+func (f *Filter) matchDomain(d linodego.Domain) bool {
+	return f.match(&d.ID, d.Domain, nil, &d.Tags)
+}
+
+// matchBucket applies the filter to a bucket; buckets have neither IDs nor tags
+func (f *Filter) matchBucket(b linodego.ObjectStorageBucket) bool {
+	return f.match(nil, b.Label, &b.Region, nil)
+}
+
+// containsAny returns true if any of values is in s
+func containsAny(s []string, values []string) bool {
+	for _, v := range values {
+		if slices.Contains(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseIDs converts a list of (string) IDs into ints
+func ParseIDs(ids []string) ([]int, error) {
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		i, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, i)
+	}
+	return result, nil
+}
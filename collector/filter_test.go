@@ -0,0 +1,194 @@
+package collector
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/linode/linodego"
+)
+
+func TestFilterMatch(t *testing.T) {
+	instance := linodego.Instance{
+		ID:     123,
+		Label:  "web-1",
+		Region: "us-east",
+		Tags:   []string{"env:prod", "web"},
+	}
+	for _, test := range []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{name: "nil", filter: nil, want: true},
+		{name: "empty", filter: &Filter{}, want: true},
+
+		{name: "include tag", filter: &Filter{IncludeTags: []string{"web"}}, want: true},
+		{name: "include any tag", filter: &Filter{IncludeTags: []string{"db", "web"}}, want: true},
+		{name: "include other tag", filter: &Filter{IncludeTags: []string{"db"}}, want: false},
+		{name: "exclude tag", filter: &Filter{ExcludeTags: []string{"env:prod"}}, want: false},
+		{name: "exclude other tag", filter: &Filter{ExcludeTags: []string{"env:dev"}}, want: true},
+
+		{name: "include region", filter: &Filter{IncludeRegions: []string{"us-east"}}, want: true},
+		{name: "include regions", filter: &Filter{IncludeRegions: []string{"eu-west", "us-east"}}, want: true},
+		{name: "include other region", filter: &Filter{IncludeRegions: []string{"eu-west"}}, want: false},
+		{name: "exclude region", filter: &Filter{ExcludeRegions: []string{"us-east"}}, want: false},
+		{name: "exclude other region", filter: &Filter{ExcludeRegions: []string{"eu-west"}}, want: true},
+
+		{name: "include ID", filter: &Filter{IncludeIDs: []int{123}}, want: true},
+		{name: "include other ID", filter: &Filter{IncludeIDs: []int{456}}, want: false},
+		{name: "exclude ID", filter: &Filter{ExcludeIDs: []int{123}}, want: false},
+		{name: "exclude other ID", filter: &Filter{ExcludeIDs: []int{456}}, want: true},
+
+		{name: "include label", filter: &Filter{IncludeLabel: regexp.MustCompile("^web-")}, want: true},
+		{name: "include other label", filter: &Filter{IncludeLabel: regexp.MustCompile("^db-")}, want: false},
+		{name: "exclude label", filter: &Filter{ExcludeLabel: regexp.MustCompile("-1$")}, want: false},
+
+		// Include criteria must all be satisfied
+		{name: "include tag and region", filter: &Filter{IncludeTags: []string{"web"}, IncludeRegions: []string{"us-east"}}, want: true},
+		{name: "include tag not region", filter: &Filter{IncludeTags: []string{"web"}, IncludeRegions: []string{"eu-west"}}, want: false},
+
+		// Exclude criteria take precedence over include criteria
+		{name: "include and exclude tag", filter: &Filter{IncludeTags: []string{"web"}, ExcludeTags: []string{"web"}}, want: false},
+		{name: "include tag exclude region", filter: &Filter{IncludeTags: []string{"web"}, ExcludeRegions: []string{"us-east"}}, want: false},
+		{name: "include and exclude ID", filter: &Filter{IncludeIDs: []int{123}, ExcludeIDs: []int{123}}, want: false},
+		{name: "include region exclude label", filter: &Filter{IncludeRegions: []string{"us-east"}, ExcludeLabel: regexp.MustCompile("web")}, want: false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.filter.matchInstance(instance); got != test.want {
+				t.Errorf("got %t; want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFilterMatchInapplicable(t *testing.T) {
+	// Domains have no region and buckets have neither IDs nor tags; these criteria are ignored
+	domain := linodego.Domain{
+		ID:     301,
+		Domain: "example.com",
+		Tags:   []string{"web"},
+	}
+	bucket := linodego.ObjectStorageBucket{
+		Label:  "assets",
+		Region: "us-east",
+	}
+	for _, test := range []struct {
+		name   string
+		filter *Filter
+		domain bool
+		bucket bool
+	}{
+		{name: "include region", filter: &Filter{IncludeRegions: []string{"eu-west"}}, domain: true, bucket: false},
+		{name: "include tag", filter: &Filter{IncludeTags: []string{"db"}}, domain: false, bucket: true},
+		{name: "exclude ID", filter: &Filter{ExcludeIDs: []int{301}}, domain: false, bucket: true},
+		{name: "include label", filter: &Filter{IncludeLabel: regexp.MustCompile("^example")}, domain: true, bucket: false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.filter.matchDomain(domain); got != test.domain {
+				t.Errorf("got domain %t; want %t", got, test.domain)
+			}
+			if got := test.filter.matchBucket(bucket); got != test.bucket {
+				t.Errorf("got bucket %t; want %t", got, test.bucket)
+			}
+		})
+	}
+}
+
+func TestFilterListOptions(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		filter *Filter
+		fields []string
+		want   string
+	}{
+		{
+			name:   "nil",
+			filter: nil,
+			fields: []string{"region", "tags"},
+		},
+		{
+			name:   "no include criteria",
+			filter: &Filter{ExcludeRegions: []string{"us-east"}, ExcludeTags: []string{"web"}},
+			fields: []string{"region", "tags"},
+		},
+		{
+			name:   "region",
+			filter: &Filter{IncludeRegions: []string{"us-east"}},
+			fields: []string{"region", "tags"},
+			want:   `{"region":"us-east"}`,
+		},
+		{
+			name:   "regions",
+			filter: &Filter{IncludeRegions: []string{"us-east", "eu-west"}},
+			fields: []string{"region", "tags"},
+			want:   `{"+or":[{"region":"us-east"},{"region":"eu-west"}]}`,
+		},
+		{
+			name:   "tags",
+			filter: &Filter{IncludeTags: []string{"web", "db"}},
+			fields: []string{"region", "tags"},
+			want:   `{"+or":[{"tags":"web"},{"tags":"db"}]}`,
+		},
+		{
+			// Only the first field with include criteria is pushed down
+			name:   "regions and tags",
+			filter: &Filter{IncludeRegions: []string{"us-east"}, IncludeTags: []string{"web"}},
+			fields: []string{"region", "tags"},
+			want:   `{"region":"us-east"}`,
+		},
+		{
+			name:   "field order",
+			filter: &Filter{IncludeRegions: []string{"us-east"}, IncludeTags: []string{"web"}},
+			fields: []string{"tags", "region"},
+			want:   `{"tags":"web"}`,
+		},
+		{
+			// e.g. Domains have no region
+			name:   "unsupported field",
+			filter: &Filter{IncludeRegions: []string{"us-east"}},
+			fields: []string{"tags"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			opts := test.filter.listOptions(test.fields...)
+			if test.want == "" {
+				if opts != nil {
+					t.Errorf("got %+v; want nil", opts)
+				}
+				return
+			}
+			if opts == nil {
+				t.Fatalf("got nil; want %s", test.want)
+			}
+			if opts.Filter != test.want {
+				t.Errorf("got %s; want %s", opts.Filter, test.want)
+			}
+		})
+	}
+}
+
+func TestParseIDs(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		ids     []string
+		want    []int
+		wantErr bool
+	}{
+		{name: "none", ids: nil, want: []int{}},
+		{name: "one", ids: []string{"123"}, want: []int{123}},
+		{name: "many", ids: []string{"123", "456"}, want: []int{123, 456}},
+		{name: "not a number", ids: []string{"123", "web-1"}, wantErr: true},
+		{name: "empty", ids: []string{""}, wantErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseIDs(test.ids)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v; want error %t", err, test.wantErr)
+			}
+			if !test.wantErr && !slices.Equal(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -12,9 +12,11 @@ import (
 
 // InstanceCollector represents a Linode Instance (aka "Linode")
 type InstanceCollector struct {
-	client linodego.Client
+	inventory *Inventory
+	tags      *TagLabels
 
 	Up     *prometheus.Desc
+	Tags   *prometheus.Desc
 	Disk   *prometheus.Desc
 	Memory *prometheus.Desc
 	CPUs   *prometheus.Desc
@@ -23,19 +25,22 @@ type InstanceCollector struct {
 }
 
 // NewInstanceCollector creates an InstanceCollector
-func NewInstanceCollector(client linodego.Client) *InstanceCollector {
+// tags may be nil if no tags are to be mapped onto labels
+func NewInstanceCollector(inventory *Inventory, tags *TagLabels) *InstanceCollector {
 	log.Println("[NewInstanceCollector] Entered")
 	subsystem := "instance"
 	labelKeys := []string{"id", "label", "region"}
 	return &InstanceCollector{
-		client: client,
+		inventory: inventory,
+		tags:      tags,
 
 		Up: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "up"),
 			"Status of Linode",
-			labelKeys,
+			append(labelKeys, tags.Names()...),
 			nil,
 		),
+		Tags: newTagInfoDesc(subsystem),
 		Disk: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "disk"),
 			"The amount of disk space in MB",
@@ -43,13 +48,13 @@ func NewInstanceCollector(client linodego.Client) *InstanceCollector {
 			nil,
 		),
 		Memory: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "cpu_max_utilization"),
+			prometheus.BuildFQName(namespace, subsystem, "memory"),
 			"The amount of RAM in MB",
 			labelKeys,
 			nil,
 		),
 		CPUs: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "io_total_blocks"),
+			prometheus.BuildFQName(namespace, subsystem, "cpus"),
 			"The number of vCPUs",
 			labelKeys,
 			nil,
@@ -59,10 +64,14 @@ func NewInstanceCollector(client linodego.Client) *InstanceCollector {
 
 // Collect implements Collector interface and is called by Prometheus to collect metrics
 func (c *InstanceCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector interface and collects metrics until ctx is done
+func (c *InstanceCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
 	log.Println("[InstanceCollector:Collect] Entered")
-	ctx := context.Background()
 
-	instances, err := c.client.ListInstances(ctx, nil)
+	instances, err := c.inventory.Instances(ctx)
 	if err != nil {
 		log.Println(err)
 		return
@@ -76,6 +85,7 @@ func (c *InstanceCollector) Collect(ch chan<- prometheus.Metric) {
 		wg.Add(1)
 		go func(i linodego.Instance) {
 			defer wg.Done()
+			defer recoverPanic(ch, c.Up)
 			log.Printf("[InstanceCollector:Collect:go] Linode ID (%d)", i.ID)
 			labelValues := []string{
 				strconv.Itoa(i.ID),
@@ -83,27 +93,32 @@ func (c *InstanceCollector) Collect(ch chan<- prometheus.Metric) {
 				i.Region,
 			}
 
-			ch <- prometheus.MustNewConstMetric(
+			ch <- newConstMetric(
 				c.Up,
 				prometheus.CounterValue,
 				1.0,
-				labelValues...,
+				append(labelValues, c.tags.Values(i.Tags)...)...,
 			)
+			collectTagInfo(ch, c.Tags, labelValues[0], i.Tags)
 
-			ch <- prometheus.MustNewConstMetric(
+			// i.Specs may be nil; only report these values when non-nil
+			if i.Specs == nil {
+				return
+			}
+			ch <- newConstMetric(
 				c.Disk,
 				prometheus.GaugeValue,
 				float64(i.Specs.Disk),
 				labelValues...,
 			)
-			ch <- prometheus.MustNewConstMetric(
+			ch <- newConstMetric(
 				c.Memory,
 				prometheus.GaugeValue,
 				float64(i.Specs.Memory),
 				labelValues...,
 			)
 
-			ch <- prometheus.MustNewConstMetric(
+			ch <- newConstMetric(
 				c.CPUs,
 				prometheus.GaugeValue,
 				float64(i.Specs.VCPUs),
@@ -120,6 +135,7 @@ func (c *InstanceCollector) Collect(ch chan<- prometheus.Metric) {
 func (c *InstanceCollector) Describe(ch chan<- *prometheus.Desc) {
 	log.Println("[InstanceCollector:Describe] Entered")
 	ch <- c.Up
+	ch <- c.Tags
 	ch <- c.Disk
 	ch <- c.Memory
 	ch <- c.CPUs
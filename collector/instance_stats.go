@@ -12,7 +12,9 @@ import (
 
 // InstanceStatsCollector represents a Linode Instance (aka "Linode") Stats
 type InstanceStatsCollector struct {
-	client linodego.Client
+	client    InstanceStatsGetter
+	inventory *Inventory
+	pool      *Pool
 
 	CPUUsage   *prometheus.Desc
 	DiskIO     *prometheus.Desc
@@ -21,12 +23,15 @@ type InstanceStatsCollector struct {
 }
 
 // NewInstanceStatsCollector creates an InstanceStatsCollector
-func NewInstanceStatsCollector(client linodego.Client) *InstanceStatsCollector {
+// pool bounds concurrent requests for Linodes' stats and may be nil (unlimited)
+func NewInstanceStatsCollector(client InstanceStatsGetter, inventory *Inventory, pool *Pool) *InstanceStatsCollector {
 	log.Println("[NewInstanceStatsCollector] Entered")
 	subsystem := "instance_stats"
 
 	return &InstanceStatsCollector{
-		client: client,
+		client:    client,
+		inventory: inventory,
+		pool:      pool,
 
 		CPUUsage: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "cpu_usage"),
@@ -57,10 +62,20 @@ func NewInstanceStatsCollector(client linodego.Client) *InstanceStatsCollector {
 
 // Collect implements Collector interface and is called by Prometheus to collect metrics
 func (c *InstanceStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector interface and collects metrics until ctx is done
+func (c *InstanceStatsCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
 	log.Println("[InstanceStatsCollector:Collect] Entered")
-	ctx := context.Background()
 
-	instances, err := c.client.ListInstances(ctx, nil)
+	// Low-priority: skipped when the Linode API rate-limit budget is low
+	if c.pool.Low() {
+		log.Println("[InstanceStatsCollector:Collect] Rate-limit budget low; skipping")
+		return
+	}
+
+	instances, err := c.inventory.Instances(ctx)
 	if err != nil {
 		log.Println(err)
 		return
@@ -74,6 +89,7 @@ func (c *InstanceStatsCollector) Collect(ch chan<- prometheus.Metric) {
 		wg.Add(1)
 		go func(i linodego.Instance) {
 			defer wg.Done()
+			defer recoverPanic(ch, c.CPUUsage)
 			log.Printf("[InstanceStatsCollector:Collect:go] Linode ID (%d)", i.ID)
 			instanceID := strconv.Itoa(i.ID)
 			labelValues := []string{
@@ -82,48 +98,43 @@ func (c *InstanceStatsCollector) Collect(ch chan<- prometheus.Metric) {
 				i.Region,
 			}
 
-			is, err := c.client.GetInstanceStats(ctx, i.ID)
+			var is *linodego.InstanceStats
+			err := c.pool.Do(ctx, func() (err error) {
+				is, err = c.client.GetInstanceStats(ctx, i.ID)
+				return err
+			})
 			if err != nil {
 				log.Println(err)
 				return
 			}
 
-			cpuUsage := is.Data.CPU[0][1]
-			ch <- prometheus.MustNewConstMetric(
-				c.CPUUsage,
-				prometheus.GaugeValue,
-				cpuUsage,
-				labelValues...,
-			)
-			diskIO := is.Data.IO.IO[0][1]
-			ch <- prometheus.MustNewConstMetric(
-				c.DiskIO,
-				prometheus.GaugeValue,
-				diskIO,
-				append(labelValues, "io")...,
-			)
-			swapIO := is.Data.IO.Swap[0][1]
-			ch <- prometheus.MustNewConstMetric(
-				c.DiskIO,
-				prometheus.GaugeValue,
-				swapIO,
-				append(labelValues, "swap")...,
-			)
-			networkIn := is.Data.NetV4.In[0][1]
-			ch <- prometheus.MustNewConstMetric(
-				c.NetworkIn,
-				prometheus.GaugeValue,
-				networkIn,
-				labelValues...,
-			)
-			networkOut := is.Data.NetV4.Out[0][1]
-			ch <- prometheus.MustNewConstMetric(
-				c.NetworkOut,
-				prometheus.GaugeValue,
-				networkOut,
-				labelValues...,
-			)
-
+			// Stats may be empty (e.g. for a newly created Linode); only report well-formed samples
+			// The latest sample is reported with its timestamp (as for NodeBalancers)
+			for _, stat := range []struct {
+				desc        *prometheus.Desc
+				samples     [][]float64
+				labelValues []string
+			}{
+				{c.CPUUsage, is.Data.CPU, labelValues},
+				{c.DiskIO, is.Data.IO.IO, append(labelValues, "io")},
+				{c.DiskIO, is.Data.IO.Swap, append(labelValues, "swap")},
+				{c.NetworkIn, is.Data.NetV4.In, labelValues},
+				{c.NetworkOut, is.Data.NetV4.Out, labelValues},
+			} {
+				ts, value, ok := latestSample(stat.samples)
+				if !ok {
+					continue
+				}
+				ch <- newMetricWithTimestamp(
+					ts,
+					newConstMetric(
+						stat.desc,
+						prometheus.GaugeValue,
+						value,
+						stat.labelValues...,
+					),
+				)
+			}
 		}(instance)
 	}
 	wg.Wait()
@@ -0,0 +1,219 @@
+package collector
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/linode/linodego"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Inventory lists Linode resources once per refresh (TTL) and shares the (filtered) lists between collectors
+// Inventory is also a Collector that reports the number of list requests it makes to the Linode API (and those it saves)
+// and the Linodes, Volumes, NodeBalancers, LKE clusters and buckets created and deleted between refreshes
+type Inventory struct {
+	client InventoryClient
+	// filter may be nil to include every resource
+	filter *Filter
+	ttl    time.Duration
+
+	instances     cache[linodego.Instance]
+	volumes       cache[linodego.Volume]
+	nodebalancers cache[linodego.NodeBalancer]
+	clusters      cache[linodego.LKECluster]
+	buckets       cache[linodego.ObjectStorageBucket]
+	domains       cache[linodego.Domain]
+
+	changes *changes
+
+	Requests *prometheus.CounterVec
+	Hits     *prometheus.CounterVec
+}
+
+// NewInventory creates an Inventory
+// Lists are refreshed when they are older than ttl; a ttl of zero lists resources on every use
+func NewInventory(client InventoryClient, filter *Filter, ttl time.Duration) *Inventory {
+	log.Println("[NewInventory] Entered")
+	subsystem := "exporter"
+	return &Inventory{
+		client: client,
+		filter: filter,
+		ttl:    ttl,
+
+		changes: newChanges(),
+
+		Requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "api_requests_total",
+				Help:      "Number of Linode API list requests made by the inventory",
+			},
+			[]string{"endpoint"},
+		),
+		Hits: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "inventory_hits_total",
+				Help:      "Number of resource lists served by the inventory without a Linode API request",
+			},
+			[]string{"endpoint"},
+		),
+	}
+}
+
+// Instances returns the (filtered) Linodes
+func (i *Inventory) Instances(ctx context.Context) ([]linodego.Instance, error) {
+	return list(i, &i.instances, "/linode/instances", func() ([]linodego.Instance, error) {
+		instances, err := i.client.ListInstances(ctx, i.filter.listOptions("region", "tags"))
+		if err != nil {
+			return nil, err
+		}
+		instances = filterItems(instances, i.filter.matchInstance)
+		i.changes.observe("linode", resourceIDs(instances, func(x linodego.Instance) string {
+			return strconv.Itoa(x.ID)
+		}), time.Now())
+		return instances, nil
+	})
+}
+
+// Volumes returns the (filtered) Volumes
+func (i *Inventory) Volumes(ctx context.Context) ([]linodego.Volume, error) {
+	return list(i, &i.volumes, "/volumes", func() ([]linodego.Volume, error) {
+		volumes, err := i.client.ListVolumes(ctx, i.filter.listOptions("tags"))
+		if err != nil {
+			return nil, err
+		}
+		volumes = filterItems(volumes, i.filter.matchVolume)
+		i.changes.observe("volume", resourceIDs(volumes, func(x linodego.Volume) string {
+			return strconv.Itoa(x.ID)
+		}), time.Now())
+		return volumes, nil
+	})
+}
+
+// NodeBalancers returns the (filtered) NodeBalancers
+func (i *Inventory) NodeBalancers(ctx context.Context) ([]linodego.NodeBalancer, error) {
+	return list(i, &i.nodebalancers, "/nodebalancers", func() ([]linodego.NodeBalancer, error) {
+		nodebalancers, err := i.client.ListNodeBalancers(ctx, i.filter.listOptions("tags"))
+		if err != nil {
+			return nil, err
+		}
+		nodebalancers = filterItems(nodebalancers, i.filter.matchNodeBalancer)
+		i.changes.observe("nodebalancer", resourceIDs(nodebalancers, func(x linodego.NodeBalancer) string {
+			return strconv.Itoa(x.ID)
+		}), time.Now())
+		return nodebalancers, nil
+	})
+}
+
+// LKEClusters returns the (filtered) Kubernetes clusters
+func (i *Inventory) LKEClusters(ctx context.Context) ([]linodego.LKECluster, error) {
+	return list(i, &i.clusters, "/lke/clusters", func() ([]linodego.LKECluster, error) {
+		clusters, err := i.client.ListLKEClusters(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		clusters = filterItems(clusters, i.filter.matchLKECluster)
+		i.changes.observe("lke_cluster", resourceIDs(clusters, func(x linodego.LKECluster) string {
+			return strconv.Itoa(x.ID)
+		}), time.Now())
+		return clusters, nil
+	})
+}
+
+// Buckets returns the (filtered) Object Storage buckets
+func (i *Inventory) Buckets(ctx context.Context) ([]linodego.ObjectStorageBucket, error) {
+	return list(i, &i.buckets, "/object-storage/buckets", func() ([]linodego.ObjectStorageBucket, error) {
+		buckets, err := i.client.ListObjectStorageBuckets(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		buckets = filterItems(buckets, i.filter.matchBucket)
+		i.changes.observe("bucket", resourceIDs(buckets, func(x linodego.ObjectStorageBucket) string {
+			return x.Region + "/" + x.Label
+		}), time.Now())
+		return buckets, nil
+	})
+}
+
+// Domains returns the (filtered) Domains
+func (i *Inventory) Domains(ctx context.Context) ([]linodego.Domain, error) {
+	return list(i, &i.domains, "/domains", func() ([]linodego.Domain, error) {
+		domains, err := i.client.ListDomains(ctx, i.filter.listOptions("tags"))
+		return filterItems(domains, i.filter.matchDomain), err
+	})
+}
+
+// Collect implements Collector interface and is called by Prometheus to collect metrics
+func (i *Inventory) Collect(ch chan<- prometheus.Metric) {
+	log.Println("[Inventory:Collect] Entered")
+	i.Requests.Collect(ch)
+	i.Hits.Collect(ch)
+	i.changes.Collect(ch)
+	log.Println("[Inventory:Collect] Completes")
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (i *Inventory) Describe(ch chan<- *prometheus.Desc) {
+	log.Println("[Inventory:Describe] Entered")
+	i.Requests.Describe(ch)
+	i.Hits.Describe(ch)
+	i.changes.Describe(ch)
+	log.Println("[Inventory:Describe] Completes")
+}
+
+// cache holds a list of resources until it expires
+type cache[T any] struct {
+	mu      sync.Mutex
+	items   []T
+	expires time.Time
+}
+
+// get returns the cached list (and true) or, if it has expired, refreshes it using fetch
+// Concurrent callers wait for a single refresh; failed refreshes are not cached
+func (c *cache[T]) get(ttl time.Duration, fetch func() ([]T, error)) ([]T, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(c.expires) {
+		return c.items, true, nil
+	}
+
+	items, err := fetch()
+	if err != nil {
+		return nil, false, err
+	}
+	c.items = items
+	c.expires = now.Add(ttl)
+	return items, false, nil
+}
+
+// list returns the inventory's list of resources from c, counting requests to endpoint and those saved (hits)
+// endpoint is formatted as by transport.Endpoint (e.g. /linode/instances) so that the series may be joined
+func list[T any](i *Inventory, c *cache[T], endpoint string, fetch func() ([]T, error)) ([]T, error) {
+	items, hit, err := c.get(i.ttl, func() ([]T, error) {
+		i.Requests.WithLabelValues(endpoint).Inc()
+		return fetch()
+	})
+	if hit {
+		i.Hits.WithLabelValues(endpoint).Inc()
+	}
+	return items, err
+}
+
+// filterItems returns the items that match
+func filterItems[T any](items []T, match func(T) bool) []T {
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		if match(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
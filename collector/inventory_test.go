@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/linode-exporter/linodefake"
+	"github.com/DazWilkin/linode-exporter/transport"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInventoryRequests(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		ttl  time.Duration
+		want string
+	}{
+		{
+			// Lists are shared until they expire; the requests saved are hits
+			name: "shared",
+			ttl:  time.Minute,
+			want: `
+# HELP linode_exporter_api_requests_total Number of Linode API list requests made by the inventory
+# TYPE linode_exporter_api_requests_total counter
+linode_exporter_api_requests_total{endpoint="/linode/instances"} 1
+linode_exporter_api_requests_total{endpoint="/volumes"} 1
+# HELP linode_exporter_inventory_hits_total Number of resource lists served by the inventory without a Linode API request
+# TYPE linode_exporter_inventory_hits_total counter
+linode_exporter_inventory_hits_total{endpoint="/linode/instances"} 2
+`,
+		},
+		{
+			// A ttl of zero lists resources on every use
+			name: "unshared",
+			ttl:  0,
+			want: `
+# HELP linode_exporter_api_requests_total Number of Linode API list requests made by the inventory
+# TYPE linode_exporter_api_requests_total counter
+linode_exporter_api_requests_total{endpoint="/linode/instances"} 3
+linode_exporter_api_requests_total{endpoint="/volumes"} 1
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := linodefake.NewServer(linodefake.Fixtures())
+			defer server.Close()
+
+			inventory := NewInventory(server.Client(), nil, test.ttl)
+			for range 3 {
+				if _, err := inventory.Instances(t.Context()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := inventory.Volumes(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := testutil.CollectAndCompare(inventory, strings.NewReader(test.want),
+				"linode_exporter_api_requests_total",
+				"linode_exporter_inventory_hits_total",
+			); err != nil {
+				t.Error(err)
+			}
+			// The requests counted are those made
+			if got, want := float64(server.Requests("linode/instances")), testutil.ToFloat64(inventory.Requests.WithLabelValues("/linode/instances")); got != want {
+				t.Errorf("got %v requests; counted %v", got, want)
+			}
+		})
+	}
+
+	// Endpoints are labeled as by the API client's instrumentation so that the series may be joined
+	if got := transport.Endpoint("/v4/linode/instances"); got != "/linode/instances" {
+		t.Errorf("got %q; want the inventory's endpoint label (/linode/instances)", got)
+	}
+}
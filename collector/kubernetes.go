@@ -12,26 +12,36 @@ import (
 
 // KubernetesCollector represents a Linode Kubernetes Engine cluster (aka "LKE")
 type KubernetesCollector struct {
-	client linodego.Client
+	client    LKENodePoolLister
+	inventory *Inventory
+	pool      *Pool
+	tags      *TagLabels
 
 	Up     *prometheus.Desc
+	Tags   *prometheus.Desc
 	Pool   *prometheus.Desc
 	Linode *prometheus.Desc
 }
 
 // NewKubernetesCollector creates a KubernetesCollector
-func NewKubernetesCollector(client linodego.Client) *KubernetesCollector {
+// pool bounds concurrent requests for clusters' node pools and may be nil (unlimited)
+// tags may be nil if no tags are to be mapped onto labels
+func NewKubernetesCollector(client LKENodePoolLister, inventory *Inventory, pool *Pool, tags *TagLabels) *KubernetesCollector {
 	log.Println("[NewKubernetesCollector] Entered")
 	subsystem := "kubernetes"
 	return &KubernetesCollector{
-		client: client,
+		client:    client,
+		inventory: inventory,
+		pool:      pool,
+		tags:      tags,
 
 		Up: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "up"),
 			"Status of Kubernetes cluster",
-			[]string{"id", "label", "region", "version"},
+			append([]string{"id", "label", "region", "version"}, tags.Names()...),
 			nil,
 		),
+		Tags: newTagInfoDesc(subsystem),
 		Pool: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "pool"),
 			"Size of Kubernetes node pool",
@@ -49,10 +59,14 @@ func NewKubernetesCollector(client linodego.Client) *KubernetesCollector {
 
 // Collect implements Collector interface and is called by Prometheus to collect metrics
 func (c *KubernetesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector interface and collects metrics until ctx is done
+func (c *KubernetesCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
 	log.Println("[KubernetesCollector:Collect] Entered")
-	ctx := context.Background()
 
-	clusters, err := c.client.ListLKEClusters(ctx, nil)
+	clusters, err := c.inventory.LKEClusters(ctx)
 	if err != nil {
 		log.Println(err)
 		return
@@ -64,14 +78,22 @@ func (c *KubernetesCollector) Collect(ch chan<- prometheus.Metric) {
 		wg.Add(1)
 		go func(k linodego.LKECluster) {
 			defer wg.Done()
-			ch <- prometheus.MustNewConstMetric(
+			defer recoverPanic(ch, c.Up)
+			labelValues := []string{
+				strconv.Itoa(k.ID), k.Label, k.Region, k.K8sVersion,
+			}
+			ch <- newConstMetric(
 				c.Up,
 				prometheus.CounterValue,
 				1.0,
-				// Label Values
-				strconv.Itoa(k.ID), k.Label, k.Region, k.K8sVersion,
+				append(labelValues, c.tags.Values(k.Tags)...)...,
 			)
-			pools, err := c.client.ListLKENodePools(ctx, k.ID, nil)
+			collectTagInfo(ch, c.Tags, labelValues[0], k.Tags)
+			var pools []linodego.LKENodePool
+			err := c.pool.Do(ctx, func() (err error) {
+				pools, err = c.client.ListLKENodePools(ctx, k.ID, nil)
+				return err
+			})
 			if err != nil {
 				log.Println(err)
 				return
@@ -82,7 +104,8 @@ func (c *KubernetesCollector) Collect(ch chan<- prometheus.Metric) {
 				wg.Add(1)
 				go func(p linodego.LKENodePool) {
 					defer wg.Done()
-					ch <- prometheus.MustNewConstMetric(
+					defer recoverPanic(ch, c.Pool)
+					ch <- newConstMetric(
 						c.Pool,
 						prometheus.GaugeValue,
 						float64(p.Count),
@@ -91,7 +114,7 @@ func (c *KubernetesCollector) Collect(ch chan<- prometheus.Metric) {
 					)
 					log.Printf("[KubernetesCollector:Collect] Cluster:%d Pool:%d", k.ID, p.ID)
 					for _, l := range p.Linodes {
-						ch <- prometheus.MustNewConstMetric(
+						ch <- newConstMetric(
 							c.Linode,
 							prometheus.CounterValue,
 							// Metric will be 1 if LKELinodeReady, 0 otherwise
@@ -121,6 +144,7 @@ func (c *KubernetesCollector) Collect(ch chan<- prometheus.Metric) {
 func (c *KubernetesCollector) Describe(ch chan<- *prometheus.Desc) {
 	log.Println("[KubernetesCollector:Describe] Entered")
 	ch <- c.Up
+	ch <- c.Tags
 	ch <- c.Pool
 	ch <- c.Linode
 	log.Println("[KubernetesCollector:Describe] Completes")
@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"context"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Budget reports whether the Linode API rate-limit budget is low
+type Budget interface {
+	Low() bool
+}
+
+// Limiter bounds the number of concurrent Linode API requests made by collectors
+// Each collector's Pool is bounded by the per-collector limit and all Pools share the global limit
+// Limiter is also a Collector that reports the number of in-flight requests by collector
+type Limiter struct {
+	global       chan struct{}
+	perCollector int
+	// budget may be nil if the rate-limit budget is unknown
+	budget Budget
+
+	InFlight *prometheus.GaugeVec
+}
+
+// NewLimiter creates a Limiter
+// A limit of zero (or less) is unlimited; budget may be nil
+func NewLimiter(global, perCollector int, budget Budget) *Limiter {
+	log.Println("[NewLimiter] Entered")
+	subsystem := "exporter"
+	log.Printf("[NewLimiter] global=%d perCollector=%d", global, perCollector)
+	return &Limiter{
+		global:       newSemaphore(global),
+		perCollector: perCollector,
+		budget:       budget,
+
+		InFlight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "api_in_flight_requests",
+				Help:      "Number of in-flight Linode API requests by collector",
+			},
+			[]string{"collector"},
+		),
+	}
+}
+
+// Pool returns a new Pool for the named collector
+func (l *Limiter) Pool(name string) *Pool {
+	return &Pool{
+		local:    newSemaphore(l.perCollector),
+		global:   l.global,
+		budget:   l.budget,
+		inFlight: l.InFlight.WithLabelValues(name),
+	}
+}
+
+// Collect implements Collector interface and is called by Prometheus to collect metrics
+func (l *Limiter) Collect(ch chan<- prometheus.Metric) {
+	log.Println("[Limiter:Collect] Entered")
+	l.InFlight.Collect(ch)
+	log.Println("[Limiter:Collect] Completes")
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (l *Limiter) Describe(ch chan<- *prometheus.Desc) {
+	log.Println("[Limiter:Describe] Entered")
+	l.InFlight.Describe(ch)
+	log.Println("[Limiter:Describe] Completes")
+}
+
+// Pool bounds a collector's concurrent Linode API requests
+// A nil *Pool is valid and is unlimited
+type Pool struct {
+	local    chan struct{}
+	global   chan struct{}
+	budget   Budget
+	inFlight prometheus.Gauge
+}
+
+// Low returns true if the rate-limit budget is low and low-priority work should be skipped
+func (p *Pool) Low() bool {
+	return p != nil && p.budget != nil && p.budget.Low()
+}
+
+// Do runs f once there is capacity in both the collector's and the global limit, returning f's error
+// If ctx is done before there is capacity, f is not run and ctx's error is returned
+// Calls to Do must not be nested
+func (p *Pool) Do(ctx context.Context, f func() error) error {
+	if p == nil {
+		return f()
+	}
+	if err := acquire(ctx, p.local); err != nil {
+		return err
+	}
+	defer release(p.local)
+	if err := acquire(ctx, p.global); err != nil {
+		return err
+	}
+	defer release(p.global)
+
+	p.inFlight.Inc()
+	defer p.inFlight.Dec()
+	return f()
+}
+
+// newSemaphore returns a semaphore of the given size (or nil, unlimited, if size is zero or less)
+func newSemaphore(size int) chan struct{} {
+	if size <= 0 {
+		return nil
+	}
+	return make(chan struct{}, size)
+}
+
+func acquire(ctx context.Context, s chan struct{}) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func release(s chan struct{}) {
+	if s != nil {
+		<-s
+	}
+}
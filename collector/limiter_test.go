@@ -0,0 +1,216 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// concurrency tracks the current and maximum number of concurrent calls
+type concurrency struct {
+	current atomic.Int32
+	max     atomic.Int32
+}
+
+// call is a function for Pool.Do that holds its slot for a while
+func (c *concurrency) call() error {
+	n := c.current.Add(1)
+	defer c.current.Add(-1)
+	for {
+		m := c.max.Load()
+		if n <= m || c.max.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return nil
+}
+
+func TestPoolPerCollectorLimit(t *testing.T) {
+	const limit = 2
+	l := NewLimiter(0, limit, nil)
+	pool := l.Pool("instance_stats")
+
+	var c concurrency
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pool.Do(t.Context(), c.call); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := c.max.Load(); got != limit {
+		t.Errorf("got %d concurrent calls; want %d", got, limit)
+	}
+}
+
+func TestPoolGlobalLimit(t *testing.T) {
+	const limit = 3
+	l := NewLimiter(limit, 2, nil)
+
+	// Each Pool permits 2 but together they may not exceed the global limit
+	var c concurrency
+	var wg sync.WaitGroup
+	for _, name := range []string{"instance_stats", "nodebalancer_stats", "objectstorage"} {
+		pool := l.Pool(name)
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := pool.Do(t.Context(), c.call); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if got := c.max.Load(); got > limit {
+		t.Errorf("got %d concurrent calls; want at most %d", got, limit)
+	}
+}
+
+func TestPoolUnlimited(t *testing.T) {
+	const n = 10
+	for _, test := range []struct {
+		name string
+		pool *Pool
+	}{
+		{name: "nil", pool: nil},
+		{name: "zero limits", pool: NewLimiter(0, 0, nil).Pool("instance_stats")},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// Every call waits for all the others so the calls complete only if none are limited
+			ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+			defer cancel()
+			var arrived sync.WaitGroup
+			arrived.Add(n)
+			all := make(chan struct{})
+			go func() {
+				arrived.Wait()
+				close(all)
+			}()
+
+			var wg sync.WaitGroup
+			for range n {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					err := test.pool.Do(ctx, func() error {
+						arrived.Done()
+						select {
+						case <-all:
+							return nil
+						case <-ctx.Done():
+							return ctx.Err()
+						}
+					})
+					if err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestPoolContextDone(t *testing.T) {
+	pool := NewLimiter(1, 1, nil).Pool("instance_stats")
+
+	held := make(chan struct{})
+	done := make(chan struct{})
+	go pool.Do(t.Context(), func() error {
+		close(held)
+		<-done
+		return nil
+	})
+	defer close(done)
+	<-held
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	ran := false
+	err := pool.Do(ctx, func() error {
+		ran = true
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v; want %v", err, context.DeadlineExceeded)
+	}
+	if ran {
+		t.Error("f ran without capacity")
+	}
+}
+
+func TestPoolInFlight(t *testing.T) {
+	l := NewLimiter(0, 0, nil)
+	pool := l.Pool("instance_stats")
+	gauge := l.InFlight.WithLabelValues("instance_stats")
+
+	const n = 3
+	var started sync.WaitGroup
+	started.Add(n)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pool.Do(t.Context(), func() error {
+				started.Done()
+				<-done
+				return nil
+			})
+		}()
+	}
+	started.Wait()
+	if got := testutil.ToFloat64(gauge); got != n {
+		t.Errorf("got %v in-flight requests; want %d", got, n)
+	}
+	if got := testutil.ToFloat64(l.InFlight.WithLabelValues("tag")); got != 0 {
+		t.Errorf("got %v in-flight requests for another collector; want 0", got)
+	}
+
+	close(done)
+	wg.Wait()
+	if got := testutil.ToFloat64(gauge); got != 0 {
+		t.Errorf("got %v in-flight requests; want 0", got)
+	}
+}
+
+// budget is a Budget with a fixed answer
+type budget bool
+
+func (b budget) Low() bool {
+	return bool(b)
+}
+
+func TestPoolLow(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		pool *Pool
+		want bool
+	}{
+		{name: "nil pool", pool: nil, want: false},
+		{name: "nil budget", pool: NewLimiter(0, 0, nil).Pool("tag"), want: false},
+		{name: "budget", pool: NewLimiter(0, 0, budget(false)).Pool("tag"), want: false},
+		{name: "low budget", pool: NewLimiter(0, 0, budget(true)).Pool("tag"), want: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.pool.Low(); got != test.want {
+				t.Errorf("got %t; want %t", got, test.want)
+			}
+		})
+	}
+}
@@ -5,68 +5,95 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/linode/linodego"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// bytesPerMB converts the Linode API's NodeBalancer transfer values (MB) into bytes
+const bytesPerMB = 1024 * 1024
+
 // NodeBalancerCollector represents a Linode NodeBalancer
 type NodeBalancerCollector struct {
-	client linodego.Client
+	inventory *Inventory
+	tags      *TagLabels
 
 	Up            *prometheus.Desc
+	Tags          *prometheus.Desc
 	TransferTotal *prometheus.Desc
 	TransferOut   *prometheus.Desc
 	TransferIn    *prometheus.Desc
+	TransferStart *prometheus.Desc
 }
 
 // NewNodeBalancerCollector creates a NodeBalancerCollector
-func NewNodeBalancerCollector(client linodego.Client) *NodeBalancerCollector {
+// tags may be nil if no tags are to be mapped onto labels
+func NewNodeBalancerCollector(inventory *Inventory, tags *TagLabels) *NodeBalancerCollector {
 	log.Println("[NewNodeBalancerCollector] Entered")
 	subsystem := "nodebalancer"
 	labelKeys := []string{"id", "label", "region"}
 	return &NodeBalancerCollector{
-		client: client,
+		inventory: inventory,
+		tags:      tags,
 
 		Up: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "up"),
 			"Status of NodeBalancer",
-			labelKeys,
+			append(labelKeys, tags.Names()...),
 			nil,
 		),
+		Tags: newTagInfoDesc(subsystem),
 		TransferTotal: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "transfer_total_bytes"),
-			"MB transferred this month by the NodeBalancer",
+			"Bytes transferred this month by the NodeBalancer (month-to-date; resets at the start of each calendar month)",
 			labelKeys,
 			nil,
 		),
 		TransferOut: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "transfer_out_bytes"),
-			"MB transferred out this month by the NodeBalancer",
+			"Bytes transferred out this month by the NodeBalancer (month-to-date; resets at the start of each calendar month)",
 			labelKeys,
 			nil,
 		),
 		TransferIn: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "transfer_in_bytes"),
-			"MB transferred in this month by the NodeBalancer",
+			"Bytes transferred in this month by the NodeBalancer (month-to-date; resets at the start of each calendar month)",
 			labelKeys,
 			nil,
 		),
+		TransferStart: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "transfer_month_start_timestamp_seconds"),
+			"Start (UTC) of the current calendar month by the exporter's clock; the Linode API does not report the billing period from which the NodeBalancer transfer counters are measured",
+			nil,
+			nil,
+		),
 	}
 }
 
 // Collect implements Collector interface and is called by Prometheus to collect metrics
 func (c *NodeBalancerCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector interface and collects metrics until ctx is done
+func (c *NodeBalancerCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
 	log.Println("[NodeBalancerCollector:Collect] Entered")
-	ctx := context.Background()
 
-	nodebalancers, err := c.client.ListNodeBalancers(ctx, nil)
+	nodebalancers, err := c.inventory.NodeBalancers(ctx)
 	if err != nil {
 		log.Println(err)
 		return
 	}
 	log.Printf("[NodeBalancerCollector:Collect] len(nodebalancers)=%d", len(nodebalancers))
 
+	// Transfer counters reset when the calendar month (by the exporter's clock) changes
+	ch <- newConstMetric(
+		c.TransferStart,
+		prometheus.GaugeValue,
+		float64(monthStart(time.Now()).Unix()),
+	)
+
 	var wg sync.WaitGroup
 	for _, nodebalancer := range nodebalancers {
 		log.Printf("[NodeBalancerCollector:Collect] NodeBalancer ID (%d)", nodebalancer.ID)
@@ -74,43 +101,51 @@ func (c *NodeBalancerCollector) Collect(ch chan<- prometheus.Metric) {
 		wg.Add(1)
 		go func(nb linodego.NodeBalancer) {
 			defer wg.Done()
+			defer recoverPanic(ch, c.Up)
 			log.Printf("[NodeBalancerCollector:Collect:go] NodeBalancer ID (%d)", nb.ID)
+			// nb.Label may be nil
+			label := ""
+			if nb.Label != nil {
+				label = *nb.Label
+			}
 			labelValues := []string{
 				fmt.Sprintf("%d", nb.ID),
-				*nb.Label,
-				//TODO(dazwilkin) NodeBalancer includes Tags too but these appear not key=value pairs
+				label,
 				nb.Region,
 			}
 
-			ch <- prometheus.MustNewConstMetric(
+			// Tags are free-form; those of the form key:value (or key=value) may be mapped onto labels
+			ch <- newConstMetric(
 				c.Up,
 				prometheus.GaugeValue,
 				1.0,
-				labelValues...,
+				append(labelValues, c.tags.Values(nb.Tags)...)...,
 			)
+			collectTagInfo(ch, c.Tags, labelValues[0], nb.Tags)
 
 			// nb.Transfer.[Total|Out|In] may be nil; only report these values when non-nil
+			// Values are month-to-date MB and are reported as (monthly resetting) counters in bytes
 			if nb.Transfer.Total != nil {
-				ch <- prometheus.MustNewConstMetric(
+				ch <- newConstMetric(
 					c.TransferTotal,
-					prometheus.GaugeValue,
-					*nb.Transfer.Total,
+					prometheus.CounterValue,
+					*nb.Transfer.Total*bytesPerMB,
 					labelValues...,
 				)
 			}
 			if nb.Transfer.Out != nil {
-				ch <- prometheus.MustNewConstMetric(
+				ch <- newConstMetric(
 					c.TransferOut,
-					prometheus.GaugeValue,
-					*nb.Transfer.Out,
+					prometheus.CounterValue,
+					*nb.Transfer.Out*bytesPerMB,
 					labelValues...,
 				)
 			}
 			if nb.Transfer.In != nil {
-				ch <- prometheus.MustNewConstMetric(
+				ch <- newConstMetric(
 					c.TransferIn,
-					prometheus.GaugeValue,
-					*nb.Transfer.In,
+					prometheus.CounterValue,
+					*nb.Transfer.In*bytesPerMB,
 					labelValues...,
 				)
 			}
@@ -125,8 +160,16 @@ func (c *NodeBalancerCollector) Collect(ch chan<- prometheus.Metric) {
 func (c *NodeBalancerCollector) Describe(ch chan<- *prometheus.Desc) {
 	log.Println("[NodeBalancerCollector:Describe] Entered")
 	ch <- c.Up
+	ch <- c.Tags
 	ch <- c.TransferTotal
 	ch <- c.TransferOut
 	ch <- c.TransferIn
+	ch <- c.TransferStart
 	log.Println("[NodeBalancerCollector:Describe] Completes")
 }
+
+// monthStart returns the start (UTC) of the month containing t
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+
+	"github.com/linode/linodego"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NodeBalancerStatsCollector represents a Linode NodeBalancer Stats
+type NodeBalancerStatsCollector struct {
+	client    NodeBalancerStatsGetter
+	inventory *Inventory
+	pool      *Pool
+
+	Connections *prometheus.Desc
+	TrafficIn   *prometheus.Desc
+	TrafficOut  *prometheus.Desc
+}
+
+// NewNodeBalancerStatsCollector creates a NodeBalancerStatsCollector
+// pool bounds concurrent requests for NodeBalancers' stats and may be nil (unlimited)
+func NewNodeBalancerStatsCollector(client NodeBalancerStatsGetter, inventory *Inventory, pool *Pool) *NodeBalancerStatsCollector {
+	log.Println("[NewNodeBalancerStatsCollector] Entered")
+	subsystem := "nodebalancer_stats"
+	labelKeys := []string{"id", "label", "region"}
+	return &NodeBalancerStatsCollector{
+		client:    client,
+		inventory: inventory,
+		pool:      pool,
+
+		Connections: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "connections"),
+			"Connections per second for NodeBalancer (latest sample)",
+			labelKeys,
+			nil,
+		),
+		TrafficIn: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "traffic_in"),
+			"Incoming traffic in bits per second for NodeBalancer (latest sample)",
+			labelKeys,
+			nil,
+		),
+		TrafficOut: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "traffic_out"),
+			"Outgoing traffic in bits per second for NodeBalancer (latest sample)",
+			labelKeys,
+			nil,
+		),
+	}
+}
+
+// Collect implements Collector interface and is called by Prometheus to collect metrics
+func (c *NodeBalancerStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector interface and collects metrics until ctx is done
+func (c *NodeBalancerStatsCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	log.Println("[NodeBalancerStatsCollector:Collect] Entered")
+
+	// Low-priority: skipped when the Linode API rate-limit budget is low
+	if c.pool.Low() {
+		log.Println("[NodeBalancerStatsCollector:Collect] Rate-limit budget low; skipping")
+		return
+	}
+
+	nodebalancers, err := c.inventory.NodeBalancers(ctx)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	log.Printf("[NodeBalancerStatsCollector:Collect] len(nodebalancers)=%d", len(nodebalancers))
+
+	var wg sync.WaitGroup
+	for _, nodebalancer := range nodebalancers {
+		log.Printf("[NodeBalancerStatsCollector:Collect] NodeBalancer ID (%d)", nodebalancer.ID)
+
+		wg.Add(1)
+		go func(nb linodego.NodeBalancer) {
+			defer wg.Done()
+			defer recoverPanic(ch, c.Connections)
+			log.Printf("[NodeBalancerStatsCollector:Collect:go] NodeBalancer ID (%d)", nb.ID)
+			label := ""
+			if nb.Label != nil {
+				label = *nb.Label
+			}
+			labelValues := []string{
+				strconv.Itoa(nb.ID),
+				label,
+				nb.Region,
+			}
+
+			var nbs *linodego.NodeBalancerStats
+			err := c.pool.Do(ctx, func() (err error) {
+				nbs, err = c.client.GetNodeBalancerStats(ctx, nb.ID)
+				return err
+			})
+			if err != nil {
+				log.Println(err)
+				return
+			}
+
+			// Stats are time-series of [timestamp (ms), value] pairs; any of these may be empty
+			for _, s := range []struct {
+				desc    *prometheus.Desc
+				samples [][]float64
+			}{
+				{c.Connections, nbs.Data.Connections},
+				{c.TrafficIn, nbs.Data.Traffic.In},
+				{c.TrafficOut, nbs.Data.Traffic.Out},
+			} {
+				ts, value, ok := latestSample(s.samples)
+				if !ok {
+					log.Printf("[NodeBalancerStatsCollector:Collect:go] NodeBalancer ID (%d) no samples for %s", nb.ID, s.desc)
+					continue
+				}
+				ch <- newMetricWithTimestamp(
+					ts,
+					newConstMetric(
+						s.desc,
+						prometheus.GaugeValue,
+						value,
+						labelValues...,
+					),
+				)
+			}
+		}(nodebalancer)
+	}
+	wg.Wait()
+	log.Println("[NodeBalancerStatsCollector:Collect] Completes")
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (c *NodeBalancerStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	log.Println("[NodeBalancerStatsCollector:Describe] Entered")
+	ch <- c.Connections
+	ch <- c.TrafficIn
+	ch <- c.TrafficOut
+	log.Println("[NodeBalancerStatsCollector:Describe] Completes")
+}
@@ -3,7 +3,9 @@ package collector
 import (
 	"context"
 	"log"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/linode/linodego"
 	"github.com/prometheus/client_golang/prometheus"
@@ -11,19 +13,43 @@ import (
 
 // ObjectStorageCollector represents a Linode object storage bucket
 type ObjectStorageCollector struct {
-	client linodego.Client
+	client    ObjectStorageClient
+	inventory *Inventory
+	pool      *Pool
+	// probe is optional; when nil, buckets are not probed through the S3 API
+	probe *S3Probe
 
 	Size         *prometheus.Desc
 	ObjectsCount *prometheus.Desc
+	BucketACL    *prometheus.Desc
+	BucketCORS   *prometheus.Desc
+
+	Endpoint   *prometheus.Desc
+	QuotaLimit *prometheus.Desc
+	QuotaUsage *prometheus.Desc
+
+	Keys         *prometheus.Desc
+	KeyBucket    *prometheus.Desc
+	TransferUsed *prometheus.Desc
+
+	ProbeSuccess   *prometheus.Desc
+	ProbeDuration  *prometheus.Desc
+	ProbeNewestAge *prometheus.Desc
 }
 
 // NewObjectStorageCollector creates a ObjectStorageCollector
-func NewObjectStorageCollector(client linodego.Client) *ObjectStorageCollector {
+// pool bounds concurrent per-bucket and per-quota requests and may be nil (unlimited)
+// probe may be nil to disable probing buckets through the S3 API
+func NewObjectStorageCollector(client ObjectStorageClient, inventory *Inventory, pool *Pool, probe *S3Probe) *ObjectStorageCollector {
 	log.Println("[NewObjectStorageCollector] Entered")
 	subsystem := "objectstorage"
 	labelKeys := []string{"label", "region"}
+	quotaLabelKeys := []string{"quota_id", "quota_name", "endpoint", "endpoint_type", "resource_metric"}
 	return &ObjectStorageCollector{
-		client: client,
+		client:    client,
+		inventory: inventory,
+		pool:      pool,
+		probe:     probe,
 
 		Size: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "size_bytes"),
@@ -37,15 +63,126 @@ func NewObjectStorageCollector(client linodego.Client) *ObjectStorageCollector {
 			labelKeys,
 			nil,
 		),
+		BucketACL: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "bucket_acl_info"),
+			"A metric with a constant value of '1' labeled with the bucket's canned ACL",
+			append(labelKeys, "acl"),
+			nil,
+		),
+		BucketCORS: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "bucket_cors_enabled"),
+			"Whether CORS is enabled (1) or disabled (0) for a bucket",
+			labelKeys,
+			nil,
+		),
+		Endpoint: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "endpoint_info"),
+			"A metric with a constant value of '1' labeled with an Object Storage endpoint available to the account",
+			[]string{"region", "endpoint", "endpoint_type"},
+			nil,
+		),
+		QuotaLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "quota_limit"),
+			"Limit of an Object Storage quota applied to the account",
+			quotaLabelKeys,
+			nil,
+		),
+		QuotaUsage: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "quota_usage"),
+			"Current usage of an Object Storage quota applied to the account",
+			quotaLabelKeys,
+			nil,
+		),
+		Keys: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "keys"),
+			"Count of Object Storage access keys",
+			[]string{"limited"},
+			nil,
+		),
+		KeyBucket: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "key_bucket_access"),
+			"A metric with a constant value of '1' labeled with the permissions a limited access key has on a bucket",
+			[]string{"id", "label", "bucket", "region", "permissions"},
+			nil,
+		),
+		TransferUsed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "transfer_used_bytes"),
+			"Outbound data transferred this month by the account's buckets (in bytes)",
+			nil,
+			nil,
+		),
+		ProbeSuccess: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "probe_success"),
+			"Whether listing the bucket through the S3 API succeeded (1) or failed (0)",
+			labelKeys,
+			nil,
+		),
+		ProbeDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "probe_duration_seconds"),
+			"Latency of listing the bucket through the S3 API",
+			labelKeys,
+			nil,
+		),
+		ProbeNewestAge: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "probe_newest_object_age_seconds"),
+			"Age of the newest object listed through the S3 API (first 10,000 objects only)",
+			labelKeys,
+			nil,
+		),
 	}
 }
 
 // Collect implements Collector interface and is called by Prometheus to collect metrics
 func (c *ObjectStorageCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector interface and collects metrics until ctx is done
+func (c *ObjectStorageCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
 	log.Println("[ObjectStorageCollector:Collect] Entered")
-	ctx := context.Background()
 
-	buckets, err := c.client.ListObjectStorageBuckets(ctx, nil)
+	var wg sync.WaitGroup
+	for _, collect := range []func(context.Context, chan<- prometheus.Metric){
+		c.collectBuckets,
+		c.collectEndpoints,
+		c.collectQuotas,
+		c.collectKeys,
+		c.collectTransfer,
+	} {
+		wg.Add(1)
+		go func(collect func(context.Context, chan<- prometheus.Metric)) {
+			defer wg.Done()
+			defer recoverPanic(ch, c.Size)
+			collect(ctx, ch)
+		}(collect)
+	}
+	wg.Wait()
+	log.Println("[ObjectStorageCollector:Collect] Completes")
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (c *ObjectStorageCollector) Describe(ch chan<- *prometheus.Desc) {
+	log.Println("[ObjectStorageCollector:Describe] Entered")
+	ch <- c.Size
+	ch <- c.ObjectsCount
+	ch <- c.BucketACL
+	ch <- c.BucketCORS
+	ch <- c.Endpoint
+	ch <- c.QuotaLimit
+	ch <- c.QuotaUsage
+	ch <- c.Keys
+	ch <- c.KeyBucket
+	ch <- c.TransferUsed
+	ch <- c.ProbeSuccess
+	ch <- c.ProbeDuration
+	ch <- c.ProbeNewestAge
+	log.Println("[ObjectStorageCollector:Describe] Completes")
+}
+
+// collectBuckets reports size, object count and access settings for each bucket
+// Bucket versioning is not exposed by the Linode API (only by the S3 API) and is not reported
+func (c *ObjectStorageCollector) collectBuckets(ctx context.Context, ch chan<- prometheus.Metric) {
+	buckets, err := c.inventory.Buckets(ctx)
 	if err != nil {
 		log.Println(err)
 		return
@@ -59,33 +196,236 @@ func (c *ObjectStorageCollector) Collect(ch chan<- prometheus.Metric) {
 		wg.Add(1)
 		go func(bucket linodego.ObjectStorageBucket) {
 			defer wg.Done()
+			defer recoverPanic(ch, c.BucketACL)
 			log.Printf("[ObjectStorageCollector:Collect:go] Bucket ID (%s)", bucket.Label)
 			labelValues := []string{
 				bucket.Label,
 				bucket.Region,
 			}
 
-			ch <- prometheus.MustNewConstMetric(
+			ch <- newConstMetric(
 				c.Size,
 				prometheus.GaugeValue,
 				float64(bucket.Size),
 				labelValues...,
 			)
-			ch <- prometheus.MustNewConstMetric(
+			ch <- newConstMetric(
 				c.ObjectsCount,
 				prometheus.GaugeValue,
 				float64(bucket.Objects),
 				labelValues...,
 			)
+
+			var access *linodego.ObjectStorageBucketAccessV2
+			err := c.pool.Do(ctx, func() (err error) {
+				access, err = c.client.GetObjectStorageBucketAccessV2(ctx, bucket.Region, bucket.Label)
+				return err
+			})
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			ch <- newConstMetric(
+				c.BucketACL,
+				prometheus.GaugeValue,
+				1.0,
+				append(labelValues, string(access.ACL))...,
+			)
+			// access.CorsEnabled may be nil; only report this value when non-nil
+			if access.CorsEnabled != nil {
+				ch <- newConstMetric(
+					c.BucketCORS,
+					prometheus.GaugeValue,
+					boolToFloat64(*access.CorsEnabled),
+					labelValues...,
+				)
+			}
 		}(bucket)
+
+		if c.probe != nil {
+			wg.Add(1)
+			go func(bucket linodego.ObjectStorageBucket) {
+				defer wg.Done()
+				defer recoverPanic(ch, c.ProbeSuccess)
+				c.probeBucket(ctx, ch, bucket)
+			}(bucket)
+		}
 	}
 	wg.Wait()
-	log.Println("[ObjectStorageCollector:Collect] Completes")
 }
 
-// Describe implements Collector interface and is called by Prometheus to describe metrics
-func (c *ObjectStorageCollector) Describe(ch chan<- *prometheus.Desc) {
-	log.Println("[ObjectStorageCollector:Describe] Entered")
-	ch <- c.Size
-	log.Println("[ObjectStorageCollector:Describe] Completes")
+// probeBucket reports whether the bucket is readable through the S3 API, the latency and the newest object's age
+func (c *ObjectStorageCollector) probeBucket(ctx context.Context, ch chan<- prometheus.Metric, bucket linodego.ObjectStorageBucket) {
+	log.Printf("[ObjectStorageCollector:Collect:go] Probing Bucket ID (%s)", bucket.Label)
+	labelValues := []string{
+		bucket.Label,
+		bucket.Region,
+	}
+
+	var result *S3ProbeResult
+	err := c.pool.Do(ctx, func() (err error) {
+		result, err = c.probe.Probe(ctx, bucket)
+		return err
+	})
+	if err != nil {
+		log.Println(err)
+	}
+	ch <- newConstMetric(
+		c.ProbeSuccess,
+		prometheus.GaugeValue,
+		boolToFloat64(err == nil),
+		labelValues...,
+	)
+	// result is nil if the bucket could not be reached
+	if result == nil {
+		return
+	}
+	ch <- newConstMetric(
+		c.ProbeDuration,
+		prometheus.GaugeValue,
+		result.Duration.Seconds(),
+		labelValues...,
+	)
+	if err == nil && result.Newest != nil {
+		ch <- newConstMetric(
+			c.ProbeNewestAge,
+			prometheus.GaugeValue,
+			time.Since(*result.Newest).Seconds(),
+			labelValues...,
+		)
+	}
+}
+
+// collectEndpoints reports the Object Storage endpoints available to the account
+func (c *ObjectStorageCollector) collectEndpoints(ctx context.Context, ch chan<- prometheus.Metric) {
+	endpoints, err := c.client.ListObjectStorageEndpoints(ctx, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	log.Printf("[ObjectStorageCollector:Collect] len(endpoints)=%d", len(endpoints))
+
+	for _, e := range endpoints {
+		s3Endpoint := ""
+		if e.S3Endpoint != nil {
+			s3Endpoint = *e.S3Endpoint
+		}
+		ch <- newConstMetric(
+			c.Endpoint,
+			prometheus.GaugeValue,
+			1.0,
+			e.Region, s3Endpoint, string(e.EndpointType),
+		)
+	}
+}
+
+// collectQuotas reports the limit and current usage of each Object Storage quota (per endpoint)
+func (c *ObjectStorageCollector) collectQuotas(ctx context.Context, ch chan<- prometheus.Metric) {
+	quotas, err := c.client.ListObjectStorageQuotas(ctx, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	log.Printf("[ObjectStorageCollector:Collect] len(quotas)=%d", len(quotas))
+
+	var wg sync.WaitGroup
+	for _, quota := range quotas {
+		wg.Add(1)
+		go func(q linodego.ObjectStorageQuota) {
+			defer wg.Done()
+			defer recoverPanic(ch, c.QuotaLimit)
+			labelValues := []string{
+				q.QuotaID,
+				q.QuotaName,
+				q.S3Endpoint,
+				q.EndpointType,
+				q.ResourceMetric,
+			}
+			ch <- newConstMetric(
+				c.QuotaLimit,
+				prometheus.GaugeValue,
+				float64(q.QuotaLimit),
+				labelValues...,
+			)
+
+			var usage *linodego.ObjectStorageQuotaUsage
+			err := c.pool.Do(ctx, func() (err error) {
+				usage, err = c.client.GetObjectStorageQuotaUsage(ctx, q.QuotaID)
+				return err
+			})
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			// usage.Usage may be nil; only report this value when non-nil
+			if usage.Usage != nil {
+				ch <- newConstMetric(
+					c.QuotaUsage,
+					prometheus.GaugeValue,
+					float64(*usage.Usage),
+					labelValues...,
+				)
+			}
+		}(quota)
+	}
+	wg.Wait()
+}
+
+// collectKeys reports the number of access keys and the bucket permissions of limited keys
+func (c *ObjectStorageCollector) collectKeys(ctx context.Context, ch chan<- prometheus.Metric) {
+	keys, err := c.client.ListObjectStorageKeys(ctx, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	log.Printf("[ObjectStorageCollector:Collect] len(keys)=%d", len(keys))
+
+	total := map[bool]float64{
+		false: 0,
+		true:  0,
+	}
+	for _, k := range keys {
+		total[k.Limited]++
+		if k.BucketAccess == nil {
+			continue
+		}
+		for _, a := range *k.BucketAccess {
+			ch <- newConstMetric(
+				c.KeyBucket,
+				prometheus.GaugeValue,
+				1.0,
+				strconv.Itoa(k.ID), k.Label, a.BucketName, a.Region, a.Permissions,
+			)
+		}
+	}
+	for limited, count := range total {
+		ch <- newConstMetric(
+			c.Keys,
+			prometheus.GaugeValue,
+			count,
+			strconv.FormatBool(limited),
+		)
+	}
+}
+
+// collectTransfer reports the account's Object Storage outbound transfer
+func (c *ObjectStorageCollector) collectTransfer(ctx context.Context, ch chan<- prometheus.Metric) {
+	transfer, err := c.client.GetObjectStorageTransfer(ctx)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	ch <- newConstMetric(
+		c.TransferUsed,
+		prometheus.GaugeValue,
+		float64(transfer.AmmountUsed),
+	)
+}
+
+// boolToFloat64 converts a bool into a metric value: 1 if true, 0 otherwise
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1.0
+	}
+	return 0.0
 }
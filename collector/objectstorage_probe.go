@@ -0,0 +1,234 @@
+package collector
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/linode/linodego"
+)
+
+const (
+	s3ProbeTimeout = 10 * time.Second
+	s3ProbeMaxKeys = 1000
+	// Listings are truncated after this many pages
+	s3ProbeMaxPages = 10
+
+	// SHA-256 of the empty payload sent by the probe
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3Probe probes Object Storage buckets through the S3 API using an access key
+type S3Probe struct {
+	accessKey string
+	secretKey string
+	// endpoint optionally overrides each bucket's hostname, e.g. a local S3-compatible server
+	// When set, requests are path-style: {endpoint}/{bucket}
+	endpoint string
+
+	client *http.Client
+}
+
+// S3ProbeResult is the outcome of a probe
+type S3ProbeResult struct {
+	Duration time.Duration
+	// Newest is the last modified time of the newest object listed (nil if the bucket is empty)
+	// Only the first s3ProbeMaxPages pages (of s3ProbeMaxKeys objects) of the listing are considered
+	Newest *time.Time
+}
+
+// NewS3Probe creates an S3Probe
+func NewS3Probe(accessKey, secretKey, endpoint string) *S3Probe {
+	return &S3Probe{
+		accessKey: accessKey,
+		secretKey: secretKey,
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		client: &http.Client{
+			Timeout: s3ProbeTimeout,
+		},
+	}
+}
+
+// listBucketResult is the subset of the S3 ListObjectsV2 response used by the probe
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// Probe lists the bucket's objects, confirming that the bucket is readable
+// The result is non-nil whenever the bucket responded, even if the probe failed
+func (p *S3Probe) Probe(ctx context.Context, bucket linodego.ObjectStorageBucket) (*S3ProbeResult, error) {
+	u := &url.URL{
+		Scheme: "https",
+		Host:   bucket.Hostname,
+		Path:   "/",
+	}
+	if p.endpoint != "" {
+		e, err := url.Parse(p.endpoint)
+		if err != nil {
+			return nil, err
+		}
+		u = e.JoinPath(bucket.Label)
+	}
+
+	start := time.Now()
+	var result *S3ProbeResult
+	token := ""
+	for page := 0; page < s3ProbeMaxPages; page++ {
+		list, err := p.list(ctx, u, bucket, token)
+		if list == nil {
+			return result, err
+		}
+		if result == nil {
+			result = &S3ProbeResult{}
+		}
+		result.Duration = time.Since(start)
+		if err != nil {
+			return result, err
+		}
+		for _, o := range list.Contents {
+			if result.Newest == nil || o.LastModified.After(*result.Newest) {
+				lastModified := o.LastModified
+				result.Newest = &lastModified
+			}
+		}
+		if !list.IsTruncated || list.NextContinuationToken == "" {
+			break
+		}
+		token = list.NextContinuationToken
+	}
+	return result, nil
+}
+
+// list requests a page of the bucket's ListObjectsV2 listing starting from the continuation token (if any)
+// The listing is non-nil whenever the bucket responded, even if the request failed
+func (p *S3Probe) list(ctx context.Context, u *url.URL, bucket linodego.ObjectStorageBucket, token string) (*listBucketResult, error) {
+	query := url.Values{
+		"list-type": []string{"2"},
+		"max-keys":  []string{fmt.Sprintf("%d", s3ProbeMaxKeys)},
+	}
+	if token != "" {
+		query.Set("continuation-token", token)
+	}
+	pageURL := *u
+	pageURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	p.sign(req, bucket.Region, time.Now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	list := &listBucketResult{}
+	if resp.StatusCode != http.StatusOK {
+		return list, fmt.Errorf("bucket %q returned %s", bucket.Label, resp.Status)
+	}
+	if err := xml.Unmarshal(body, list); err != nil {
+		return list, err
+	}
+	return list, nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to an (empty payload) request
+func (p *S3Probe) sign(req *http.Request, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n",
+		req.URL.Host,
+		emptyPayloadHash,
+		amzDate,
+	)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := []byte("AWS4" + p.secretKey)
+	for _, s := range []string{date, region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, s)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.accessKey,
+		scope,
+		signedHeaders,
+		signature,
+	))
+}
+
+// canonicalQuery returns the query string with sorted keys and RFC 3986 encoding
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := []string{}
+	for _, k := range keys {
+		vs := values[k]
+		sort.Strings(vs)
+		for _, v := range vs {
+			pairs = append(pairs, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode encodes s per RFC 3986 (spaces as %20, not +)
+func uriEncode(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hashHex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
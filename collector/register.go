@@ -0,0 +1,188 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Options configures the collectors created by New and Register
+// The zero value enables every collector without filtering, concurrency limits or timeouts
+type Options struct {
+	// Collectors are the names (see Catalogue) of the collectors to enable; if empty, every collector is enabled
+	Collectors []string
+	// TagLabels is an allow-list of tag keys (from key:value or key=value tags) added as tag_{key} labels
+	TagLabels []string
+	// Filter includes and excludes resources; may be nil
+	Filter *Filter
+	// InventoryTTL is the duration for which resource lists are shared between collectors; zero lists resources on every use
+	InventoryTTL time.Duration
+	// MaxConcurrency bounds concurrent per-resource Linode API requests across all collectors; zero is unlimited
+	MaxConcurrency int
+	// MaxCollectorConcurrency bounds concurrent per-resource Linode API requests per collector; zero is unlimited
+	MaxCollectorConcurrency int
+	// Budget reports whether the Linode API rate-limit budget is low (and low-priority collectors should be skipped); may be nil
+	Budget Budget
+	// Probe probes Object Storage buckets through the S3 API; may be nil
+	Probe *S3Probe
+	// Timeout bounds each collector's collection; zero is unlimited
+	Timeout time.Duration
+	// Timeouts overrides Timeout by collector name
+	Timeouts map[string]time.Duration
+}
+
+// Description describes a collector
+type Description struct {
+	Name        string
+	Description string
+}
+
+// dependencies are shared by the collectors
+type dependencies struct {
+	client    Client
+	inventory *Inventory
+	limiter   *Limiter
+	tags      *TagLabels
+	probe     *S3Probe
+}
+
+// catalogue are the collectors (other than the ExporterCollector) by name
+var catalogue = map[string]struct {
+	description string
+	new         func(name string, d dependencies) ContextCollector
+}{
+	"account": {
+		description: "Account balance and uninvoiced balance",
+		new: func(name string, d dependencies) ContextCollector {
+			return NewAccountCollector(d.client)
+		},
+	},
+	"domain": {
+		description: "Domains and their tags",
+		new: func(name string, d dependencies) ContextCollector {
+			return NewDomainCollector(d.inventory, d.tags)
+		},
+	},
+	"instance": {
+		description: "Linodes, their specs and their tags",
+		new: func(name string, d dependencies) ContextCollector {
+			return NewInstanceCollector(d.inventory, d.tags)
+		},
+	},
+	"instance_stats": {
+		description: "Linodes' CPU, disk IO and network stats (low-priority)",
+		new: func(name string, d dependencies) ContextCollector {
+			return NewInstanceStatsCollector(d.client, d.inventory, d.limiter.Pool(name))
+		},
+	},
+	"kubernetes": {
+		description: "LKE clusters, their node pools and nodes, and their tags",
+		new: func(name string, d dependencies) ContextCollector {
+			return NewKubernetesCollector(d.client, d.inventory, d.limiter.Pool(name), d.tags)
+		},
+	},
+	"nodebalancer": {
+		description: "NodeBalancers, their monthly transfer and their tags",
+		new: func(name string, d dependencies) ContextCollector {
+			return NewNodeBalancerCollector(d.inventory, d.tags)
+		},
+	},
+	"nodebalancer_stats": {
+		description: "NodeBalancers' connections and traffic stats (low-priority)",
+		new: func(name string, d dependencies) ContextCollector {
+			return NewNodeBalancerStatsCollector(d.client, d.inventory, d.limiter.Pool(name))
+		},
+	},
+	"objectstorage": {
+		description: "Object Storage buckets, endpoints, quotas, keys and transfer (and, optionally, bucket probes)",
+		new: func(name string, d dependencies) ContextCollector {
+			return NewObjectStorageCollector(d.client, d.inventory, d.limiter.Pool(name), d.probe)
+		},
+	},
+	"tag": {
+		description: "Number of objects and Linodes' resources by tag (low-priority)",
+		new: func(name string, d dependencies) ContextCollector {
+			return NewTagCollector(d.client, d.limiter.Pool(name))
+		},
+	},
+	"ticket": {
+		description: "Support tickets by status and referenced entity",
+		new: func(name string, d dependencies) ContextCollector {
+			return NewTicketCollector(d.client)
+		},
+	},
+	"volume": {
+		description: "Volumes and their tags",
+		new: func(name string, d dependencies) ContextCollector {
+			return NewVolumeCollector(d.inventory, d.tags)
+		},
+	},
+}
+
+// Catalogue returns the names and descriptions of the collectors, sorted by name
+func Catalogue() []Description {
+	descriptions := make([]Description, 0, len(catalogue))
+	for name, c := range catalogue {
+		descriptions = append(descriptions, Description{
+			Name:        name,
+			Description: c.description,
+		})
+	}
+	sort.Slice(descriptions, func(i, j int) bool {
+		return descriptions[i].Name < descriptions[j].Name
+	})
+	return descriptions
+}
+
+// New creates a Scraper for the collectors enabled by opts
+// The state the collectors share (inventory, limiter and errors) is registered with the collectors by Scraper.Register (and Scraper.Registry)
+func New(client Client, opts Options) (*Scraper, error) {
+	log.Println("[New] Entered")
+	names := opts.Collectors
+	if len(names) == 0 {
+		for _, d := range Catalogue() {
+			names = append(names, d.Name)
+		}
+	}
+
+	var tags *TagLabels
+	if len(opts.TagLabels) > 0 {
+		tags = NewTagLabels(opts.TagLabels)
+	}
+	d := dependencies{
+		client:    client,
+		inventory: NewInventory(client, opts.Filter, opts.InventoryTTL),
+		limiter:   NewLimiter(opts.MaxConcurrency, opts.MaxCollectorConcurrency, opts.Budget),
+		tags:      tags,
+		probe:     opts.Probe,
+	}
+
+	collectors := map[string]ContextCollector{}
+	for _, name := range names {
+		c, ok := catalogue[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown collector %q", name)
+		}
+		collectors[name] = c.new(name, d)
+	}
+
+	scraper := NewScraper(collectors, opts.Timeout, opts.Timeouts)
+	scraper.shared = []prometheus.Collector{d.inventory, d.limiter}
+	scraper.inventory = d.inventory
+	return scraper, nil
+}
+
+// Register registers the collectors enabled by opts (and their shared state) with registry
+// The collectors collect with context.Background() (bounded by opts' timeouts)
+// To bind collection to each scrape's context, use New and Scraper.Registry
+func Register(registry prometheus.Registerer, client Client, opts Options) error {
+	scraper, err := New(client, opts)
+	if err != nil {
+		return err
+	}
+	return scraper.Register(context.Background(), registry)
+}
@@ -0,0 +1,273 @@
+package collector
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// ContextCollector is a Collector whose collection stops when a context is done
+type ContextCollector interface {
+	prometheus.Collector
+	CollectContext(ctx context.Context, ch chan<- prometheus.Metric)
+}
+
+// Scraper binds ContextCollectors to a scrape's context with per-collector timeouts
+// Registered collectors also report the collectors' errors (invalid metrics and panics) and their shared state
+type Scraper struct {
+	collectors map[string]ContextCollector
+	timeout    time.Duration
+	timeouts   map[string]time.Duration
+	// shared are collectors of state shared by the collectors (e.g. the Inventory), if any
+	shared []prometheus.Collector
+	// inventory is the Inventory shared by the collectors, if any
+	inventory *Inventory
+
+	Errors *prometheus.CounterVec
+}
+
+// NewScraper creates a Scraper for the named collectors
+// Collectors time out after timeout unless overridden (by name) in timeouts; a timeout of zero is unlimited
+func NewScraper(collectors map[string]ContextCollector, timeout time.Duration, timeouts map[string]time.Duration) *Scraper {
+	log.Println("[NewScraper] Entered")
+	subsystem := "exporter"
+	return &Scraper{
+		collectors: collectors,
+		timeout:    timeout,
+		timeouts:   timeouts,
+
+		Errors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "collector_errors_total",
+				Help:      "Number of errors (invalid metrics and panics) by collector and reason",
+			},
+			[]string{"collector", "reason"},
+		),
+	}
+}
+
+// Inventory returns the Inventory shared by the collectors created by New (nil for Scrapers created by NewScraper)
+// Resource lists (e.g. for service discovery) obtained from it are shared with (and filtered as for) the collectors
+func (s *Scraper) Inventory() *Inventory {
+	return s.inventory
+}
+
+// Registry returns a Registry whose collectors collect until ctx is done or they time out
+func (s *Scraper) Registry(ctx context.Context) (*prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+	if err := s.Register(ctx, registry); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// Gatherer returns a Gatherer whose collectors are bound, on each Gather, to a context that is done after timeout
+// It is intended for gathering outside of a scrape (e.g. to push metrics); a timeout of zero is unlimited
+func (s *Scraper) Gatherer(timeout time.Duration) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		registry, err := s.Registry(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return registry.Gather()
+	})
+}
+
+// Register registers the collectors with registry; they collect until ctx is done or they time out
+// The collectors, their errors and their shared state are registered as a single Collector
+func (s *Scraper) Register(ctx context.Context, registry prometheus.Registerer) error {
+	sc := &scrape{
+		scraper: s,
+	}
+	for name, c := range s.collectors {
+		timeout := s.timeout
+		if t, ok := s.timeouts[name]; ok {
+			timeout = t
+		}
+		sc.collectors = append(sc.collectors, newScrapeCollector(ctx, name, c, timeout, s.Errors))
+	}
+	return registry.Register(sc)
+}
+
+// scrape is a Scraper's collectors bound to a scrape's context
+// The collectors' errors and shared state (e.g. the Inventory's requests) are collected once the collectors complete
+// so that they reflect this scrape rather than the previous one
+type scrape struct {
+	scraper    *Scraper
+	collectors []*scrapeCollector
+}
+
+// Collect implements Collector interface and is called by Prometheus to collect metrics
+func (sc *scrape) Collect(ch chan<- prometheus.Metric) {
+	log.Println("[scrape:Collect] Entered")
+	var wg sync.WaitGroup
+	for _, c := range sc.collectors {
+		wg.Add(1)
+		go func(c *scrapeCollector) {
+			defer wg.Done()
+			c.Collect(ch)
+		}(c)
+	}
+	wg.Wait()
+
+	sc.scraper.Errors.Collect(ch)
+	for _, c := range sc.scraper.shared {
+		c.Collect(ch)
+	}
+	log.Println("[scrape:Collect] Completes")
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (sc *scrape) Describe(ch chan<- *prometheus.Desc) {
+	log.Println("[scrape:Describe] Entered")
+	for _, c := range sc.collectors {
+		c.Describe(ch)
+	}
+	sc.scraper.Errors.Describe(ch)
+	for _, c := range sc.scraper.shared {
+		c.Describe(ch)
+	}
+	log.Println("[scrape:Describe] Completes")
+}
+
+// scrapeCollector is a ContextCollector bound to a scrape's context
+// It reports whether the collector completed within its timeout and how long it took
+type scrapeCollector struct {
+	ctx       context.Context
+	name      string
+	collector ContextCollector
+	timeout   time.Duration
+	errors    *prometheus.CounterVec
+
+	Success  *prometheus.Desc
+	Duration *prometheus.Desc
+}
+
+func newScrapeCollector(ctx context.Context, name string, collector ContextCollector, timeout time.Duration, errors *prometheus.CounterVec) *scrapeCollector {
+	subsystem := "exporter"
+	// The collector's name is a constant label so that each scrapeCollector's Descs are unique
+	constLabels := prometheus.Labels{"collector": name}
+	return &scrapeCollector{
+		ctx:       ctx,
+		name:      name,
+		collector: collector,
+		timeout:   timeout,
+		errors:    errors,
+
+		Success: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "collector_success"),
+			"Whether the collector completed (1) or was cancelled or timed out (0)",
+			nil,
+			constLabels,
+		),
+		Duration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "collector_duration_seconds"),
+			"Duration of the collector's collection",
+			nil,
+			constLabels,
+		),
+	}
+}
+
+// Collect implements Collector interface and is called by Prometheus to collect metrics
+// Metrics sent by the collector after it is cancelled or times out are discarded
+// A collector that panics is reported as unsuccessful
+func (c *scrapeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := c.ctx, context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(c.ctx, c.timeout)
+	}
+	defer cancel()
+
+	start := time.Now()
+	metrics := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	panicked := make(chan struct{}, 1)
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("[scrapeCollector:Collect] %s: panic: %v\n%s", c.name, r, debug.Stack())
+				panicked <- struct{}{}
+			}
+		}()
+		c.collector.CollectContext(ctx, metrics)
+	}()
+
+	success := 1.0
+	func() {
+		for {
+			select {
+			case m := <-metrics:
+				if e, ok := m.(collectError); ok {
+					c.errors.WithLabelValues(c.name, e.reason).Inc()
+				}
+				ch <- m
+			case <-done:
+				// A collector that returns because ctx is done is incomplete
+				if err := ctx.Err(); err != nil {
+					log.Printf("[scrapeCollector:Collect] %s: %v", c.name, err)
+					success = 0.0
+				}
+				return
+			case <-ctx.Done():
+				log.Printf("[scrapeCollector:Collect] %s: %v", c.name, ctx.Err())
+				success = 0.0
+				// The collector may have returned as ctx was done
+				select {
+				case <-done:
+					return
+				default:
+				}
+				// Drain (and discard) any remaining metrics so that the collector is able to complete
+				go func() {
+					for {
+						select {
+						case <-metrics:
+						case <-done:
+							return
+						}
+					}
+				}()
+				return
+			}
+		}
+	}()
+	select {
+	case <-panicked:
+		c.errors.WithLabelValues(c.name, reasonPanic).Inc()
+		success = 0.0
+	default:
+	}
+
+	ch <- newConstMetric(
+		c.Success,
+		prometheus.GaugeValue,
+		success,
+	)
+	ch <- newConstMetric(
+		c.Duration,
+		prometheus.GaugeValue,
+		time.Since(start).Seconds(),
+	)
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (c *scrapeCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+	ch <- c.Success
+	ch <- c.Duration
+}
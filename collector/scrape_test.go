@@ -0,0 +1,235 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// testCollector is a ContextCollector whose collection is f
+type testCollector struct {
+	desc *prometheus.Desc
+	f    func(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc)
+}
+
+func newTestCollector(name string, f func(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc)) *testCollector {
+	return &testCollector{
+		desc: prometheus.NewDesc("linode_test_"+name, "Test metric", []string{"n"}, nil),
+		f:    f,
+	}
+}
+
+func (c *testCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+func (c *testCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	c.f(ctx, ch, c.desc)
+}
+
+func (c *testCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// blocking returns a collection that sends a metric, blocks until ctx is done and then sends more metrics
+// finished is closed once the collection completes
+func blocking(finished chan<- struct{}) func(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+	return func(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+		defer close(finished)
+		ch <- newConstMetric(desc, prometheus.GaugeValue, 1, "before")
+		<-ctx.Done()
+		// Metrics sent after the collector is cancelled are discarded (but must not block it)
+		for _, n := range []string{"after-1", "after-2", "after-3"} {
+			ch <- newConstMetric(desc, prometheus.GaugeValue, 1, n)
+		}
+	}
+}
+
+// gathered returns a Gatherer of families that were gathered (once) from a Registry
+func gathered(families []*dto.MetricFamily) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return families, nil
+	})
+}
+
+func quick(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+	ch <- newConstMetric(desc, prometheus.GaugeValue, 1, "quick")
+}
+
+func TestScraperTimeout(t *testing.T) {
+	finished := make(chan struct{})
+	scraper := NewScraper(map[string]ContextCollector{
+		"slow":  newTestCollector("slow", blocking(finished)),
+		"quick": newTestCollector("quick", quick),
+		// A collector that gives up (returns) when it is cancelled did not complete either
+		"giving_up": newTestCollector("giving_up", func(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+			<-ctx.Done()
+		}),
+	}, time.Minute, map[string]time.Duration{
+		"slow":      50 * time.Millisecond,
+		"giving_up": 50 * time.Millisecond,
+	})
+	registry, err := scraper.Registry(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gathered in %s; want the slow collector to stop at its timeout (50ms)", elapsed)
+	}
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("the slow collector did not complete; its metrics were not drained")
+	}
+
+	want := `
+# HELP linode_exporter_collector_success Whether the collector completed (1) or was cancelled or timed out (0)
+# TYPE linode_exporter_collector_success gauge
+linode_exporter_collector_success{collector="giving_up"} 0
+linode_exporter_collector_success{collector="quick"} 1
+linode_exporter_collector_success{collector="slow"} 0
+# HELP linode_test_slow Test metric
+# TYPE linode_test_slow gauge
+linode_test_slow{n="before"} 1
+`
+	if err := testutil.GatherAndCompare(gathered(families), strings.NewReader(want), "linode_exporter_collector_success", "linode_test_slow"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScraperCancelled(t *testing.T) {
+	finished := make(chan struct{})
+	// The collector is unlimited; it stops only when the scrape's context is done
+	scraper := NewScraper(map[string]ContextCollector{
+		"slow": newTestCollector("slow", blocking(finished)),
+	}, 0, nil)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	registry, err := scraper.Registry(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gathered in %s; want the collector to stop when the scrape is cancelled (50ms)", elapsed)
+	}
+	if got, err := testutil.GatherAndCount(gathered(families), "linode_test_slow"); err != nil || got != 1 {
+		t.Errorf("got %d metrics (%v); want 1 (those sent before the scrape was cancelled)", got, err)
+	}
+	<-finished
+
+	want := `
+# HELP linode_exporter_collector_success Whether the collector completed (1) or was cancelled or timed out (0)
+# TYPE linode_exporter_collector_success gauge
+linode_exporter_collector_success{collector="slow"} 0
+`
+	if err := testutil.GatherAndCompare(gathered(families), strings.NewReader(want), "linode_exporter_collector_success"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScraperRegistryPerScrape(t *testing.T) {
+	scraper := NewScraper(map[string]ContextCollector{
+		"quick": newTestCollector("quick", quick),
+	}, time.Minute, nil)
+
+	// Each scrape has its own Registry (bound to its context); the collectors are not shared between them
+	for range 2 {
+		registry, err := scraper.Registry(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := testutil.GatherAndCount(registry, "linode_exporter_collector_success", "linode_test_quick"); err != nil || got != 2 {
+			t.Errorf("got %d metrics (%v); want 2", got, err)
+		}
+	}
+}
+
+func TestScraperErrors(t *testing.T) {
+	scraper := NewScraper(map[string]ContextCollector{
+		"quick": newTestCollector("quick", quick),
+		"panicking": newTestCollector("panicking", func(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+			ch <- newConstMetric(desc, prometheus.GaugeValue, 1, "before")
+			panic("boom")
+		}),
+		// Collectors recover panics in their goroutines with recoverPanic
+		"panicking_goroutine": newTestCollector("panicking_goroutine", func(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				defer recoverPanic(ch, desc)
+				panic("boom")
+			}()
+			<-done
+			ch <- newConstMetric(desc, prometheus.GaugeValue, 1, "after")
+		}),
+		"invalid": newTestCollector("invalid", func(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+			// Too many label values
+			ch <- newConstMetric(desc, prometheus.GaugeValue, 1, "a", "b")
+			ch <- newConstMetric(desc, prometheus.GaugeValue, 1, "valid")
+		}),
+	}, time.Minute, nil)
+	registry, err := scraper.Registry(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The invalid metrics are reported as errors but the remaining metrics are gathered
+	families, err := registry.Gather()
+	if err == nil {
+		t.Error("got nil error for invalid metrics")
+	}
+
+	want := `
+# HELP linode_exporter_collector_errors_total Number of errors (invalid metrics and panics) by collector and reason
+# TYPE linode_exporter_collector_errors_total counter
+linode_exporter_collector_errors_total{collector="invalid",reason="invalid_metric"} 1
+linode_exporter_collector_errors_total{collector="panicking",reason="panic"} 1
+linode_exporter_collector_errors_total{collector="panicking_goroutine",reason="panic"} 1
+# HELP linode_exporter_collector_success Whether the collector completed (1) or was cancelled or timed out (0)
+# TYPE linode_exporter_collector_success gauge
+linode_exporter_collector_success{collector="invalid"} 1
+linode_exporter_collector_success{collector="panicking"} 0
+linode_exporter_collector_success{collector="panicking_goroutine"} 1
+linode_exporter_collector_success{collector="quick"} 1
+# HELP linode_test_invalid Test metric
+# TYPE linode_test_invalid gauge
+linode_test_invalid{n="valid"} 1
+# HELP linode_test_panicking Test metric
+# TYPE linode_test_panicking gauge
+linode_test_panicking{n="before"} 1
+# HELP linode_test_panicking_goroutine Test metric
+# TYPE linode_test_panicking_goroutine gauge
+linode_test_panicking_goroutine{n="after"} 1
+# HELP linode_test_quick Test metric
+# TYPE linode_test_quick gauge
+linode_test_quick{n="quick"} 1
+`
+	if err := testutil.GatherAndCompare(gathered(families), strings.NewReader(want),
+		"linode_exporter_collector_errors_total",
+		"linode_exporter_collector_success",
+		"linode_test_invalid",
+		"linode_test_panicking",
+		"linode_test_panicking_goroutine",
+		"linode_test_quick",
+	); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,20 @@
+package collector
+
+import (
+	"time"
+)
+
+// Linode API stats are series of [timestamp (ms), value] pairs
+// Collectors report the latest (well-formed) sample with its timestamp rather than the time of the scrape
+// Prometheus does not mark series with explicit timestamps stale; they disappear once the lookback delta elapses
+
+// latestSample returns the timestamp and value of the most recent well-formed [timestamp (ms), value] pair
+func latestSample(samples [][]float64) (time.Time, float64, bool) {
+	for i := len(samples) - 1; i >= 0; i-- {
+		if len(samples[i]) < 2 {
+			continue
+		}
+		return time.UnixMilli(int64(samples[i][0])), samples[i][1], true
+	}
+	return time.Time{}, 0, false
+}
@@ -0,0 +1,161 @@
+package collector
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/linode/linodego"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TagCollector represents a Linode Tag and the resources to which it is applied
+type TagCollector struct {
+	client TagLister
+	pool   *Pool
+
+	Objects *prometheus.Desc
+	CPUs    *prometheus.Desc
+	Memory  *prometheus.Desc
+	Disk    *prometheus.Desc
+}
+
+// NewTagCollector creates a TagCollector
+// pool bounds concurrent requests for tagged objects and may be nil (unlimited)
+func NewTagCollector(client TagLister, pool *Pool) *TagCollector {
+	log.Println("[NewTagCollector] Entered")
+	subsystem := "tag"
+	labelKeys := []string{"tag"}
+	return &TagCollector{
+		client: client,
+		pool:   pool,
+
+		Objects: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "objects"),
+			"Number of resources (linode, volume, nodebalancer, domain, lke_cluster) with the tag",
+			append(labelKeys, "type"),
+			nil,
+		),
+		CPUs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "instance_cpus"),
+			"The total number of vCPUs of Linodes with the tag",
+			labelKeys,
+			nil,
+		),
+		Memory: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "instance_memory"),
+			"The total amount of RAM in MB of Linodes with the tag",
+			labelKeys,
+			nil,
+		),
+		Disk: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "instance_disk"),
+			"The total amount of disk space in MB of Linodes with the tag",
+			labelKeys,
+			nil,
+		),
+	}
+}
+
+// Collect implements Collector interface and is called by Prometheus to collect metrics
+func (c *TagCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector interface and collects metrics until ctx is done
+func (c *TagCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	log.Println("[TagCollector:Collect] Entered")
+
+	// Low-priority: skipped when the Linode API rate-limit budget is low
+	if c.pool.Low() {
+		log.Println("[TagCollector:Collect] Rate-limit budget low; skipping")
+		return
+	}
+
+	tags, err := c.client.ListTags(ctx, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	log.Printf("[TagCollector:Collect] len(tags)=%d", len(tags))
+
+	var wg sync.WaitGroup
+	for _, tag := range tags {
+		wg.Add(1)
+		go func(t linodego.Tag) {
+			defer wg.Done()
+			defer recoverPanic(ch, c.Objects)
+			log.Printf("[TagCollector:Collect:go] Tag (%s)", t.Label)
+
+			var objects linodego.TaggedObjectList
+			err := c.pool.Do(ctx, func() (err error) {
+				objects, err = c.client.ListTaggedObjects(ctx, t.Label, nil)
+				return err
+			})
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			so, err := objects.SortedObjects()
+			if err != nil {
+				log.Println(err)
+				return
+			}
+
+			for objectType, count := range map[string]int{
+				"linode":       len(so.Instances),
+				"volume":       len(so.Volumes),
+				"nodebalancer": len(so.NodeBalancers),
+				"domain":       len(so.Domains),
+				"lke_cluster":  len(so.LKEClusters),
+			} {
+				ch <- newConstMetric(
+					c.Objects,
+					prometheus.GaugeValue,
+					float64(count),
+					t.Label, objectType,
+				)
+			}
+
+			var cpus, memory, disk int
+			for _, i := range so.Instances {
+				if i.Specs == nil {
+					continue
+				}
+				cpus += i.Specs.VCPUs
+				memory += i.Specs.Memory
+				disk += i.Specs.Disk
+			}
+			ch <- newConstMetric(
+				c.CPUs,
+				prometheus.GaugeValue,
+				float64(cpus),
+				t.Label,
+			)
+			ch <- newConstMetric(
+				c.Memory,
+				prometheus.GaugeValue,
+				float64(memory),
+				t.Label,
+			)
+			ch <- newConstMetric(
+				c.Disk,
+				prometheus.GaugeValue,
+				float64(disk),
+				t.Label,
+			)
+		}(tag)
+	}
+	wg.Wait()
+	log.Println("[TagCollector:Collect] Completes")
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (c *TagCollector) Describe(ch chan<- *prometheus.Desc) {
+	log.Println("[TagCollector:Describe] Entered")
+	ch <- c.Objects
+	ch <- c.CPUs
+	ch <- c.Memory
+	ch <- c.Disk
+	log.Println("[TagCollector:Describe] Completes")
+}
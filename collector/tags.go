@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	tagLabelPrefix = "tag_"
+)
+
+// TagLabels maps Linode tags of the form key:value (or key=value) onto Prometheus labels
+// Only tags whose keys are in the allow-list become labels (named tag_{key})
+// A nil *TagLabels is valid and maps no tags
+type TagLabels struct {
+	keys []string
+}
+
+// NewTagLabels creates a TagLabels for the allow-listed keys
+func NewTagLabels(keys []string) *TagLabels {
+	log.Println("[NewTagLabels] Entered")
+	seen := map[string]bool{}
+	t := &TagLabels{}
+	for _, key := range keys {
+		key = sanitizeTagKey(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		t.keys = append(t.keys, key)
+	}
+	log.Printf("[NewTagLabels] keys=%v", t.keys)
+	return t
+}
+
+// Names returns the label names for the allow-listed keys
+func (t *TagLabels) Names() []string {
+	if t == nil {
+		return nil
+	}
+	names := make([]string, len(t.keys))
+	for i, key := range t.keys {
+		names[i] = tagLabelPrefix + key
+	}
+	return names
+}
+
+// Values returns the label values (in Names order) for a resource's tags
+// Keys without a matching tag have the empty value; repeated keys have their values sorted and comma-separated
+func (t *TagLabels) Values(tags []string) []string {
+	if t == nil {
+		return nil
+	}
+	matches := map[string][]string{}
+	for _, tag := range tags {
+		key, value, ok := parseTag(tag)
+		if !ok {
+			continue
+		}
+		matches[key] = append(matches[key], value)
+	}
+	values := make([]string, len(t.keys))
+	for i, key := range t.keys {
+		sort.Strings(matches[key])
+		values[i] = strings.Join(matches[key], ",")
+	}
+	return values
+}
+
+// parseTag splits a tag of the form key:value (or key=value) on the first separator
+func parseTag(tag string) (string, string, bool) {
+	i := strings.IndexAny(tag, ":=")
+	if i <= 0 {
+		return "", "", false
+	}
+	return sanitizeTagKey(tag[:i]), strings.TrimSpace(tag[i+1:]), true
+}
+
+// sanitizeTagKey converts a tag key into a valid (lower-case) Prometheus label name suffix
+func sanitizeTagKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.ToLower(strings.TrimSpace(key)))
+}
+
+// newTagInfoDesc creates the fallback metric that reports every (raw) tag applied to a resource
+func newTagInfoDesc(subsystem string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "tag_info"),
+		"A metric with a constant value of '1' for each tag applied to the resource",
+		[]string{"id", "tag"},
+		nil,
+	)
+}
+
+// collectTagInfo sends one tag info metric for each of a resource's tags
+func collectTagInfo(ch chan<- prometheus.Metric, desc *prometheus.Desc, id string, tags []string) {
+	for _, tag := range tags {
+		ch <- newConstMetric(
+			desc,
+			prometheus.GaugeValue,
+			1.0,
+			id, tag,
+		)
+	}
+}
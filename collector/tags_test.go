@@ -0,0 +1,145 @@
+package collector
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestParseTag(t *testing.T) {
+	for _, test := range []struct {
+		tag   string
+		key   string
+		value string
+		ok    bool
+	}{
+		{tag: "env:prod", key: "env", value: "prod", ok: true},
+		{tag: "env=prod", key: "env", value: "prod", ok: true},
+		// Split on the first separator
+		{tag: "env:prod:eu", key: "env", value: "prod:eu", ok: true},
+		{tag: "env=a:b", key: "env", value: "a:b", ok: true},
+		{tag: "env:a=b", key: "env", value: "a=b", ok: true},
+		// Keys are sanitised; keys and values are trimmed
+		{tag: " Env : Prod ", key: "env", value: "Prod", ok: true},
+		{tag: "Cost-Center:1234", key: "cost_center", value: "1234", ok: true},
+		{tag: "team.name=sre", key: "team_name", value: "sre", ok: true},
+		{tag: "env:", key: "env", value: "", ok: true},
+		// Tags without a key
+		{tag: "web", ok: false},
+		{tag: ":prod", ok: false},
+		{tag: "=prod", ok: false},
+		{tag: "", ok: false},
+	} {
+		t.Run(test.tag, func(t *testing.T) {
+			key, value, ok := parseTag(test.tag)
+			if ok != test.ok || key != test.key || value != test.value {
+				t.Errorf("got (%q, %q, %t); want (%q, %q, %t)", key, value, ok, test.key, test.value, test.ok)
+			}
+		})
+	}
+}
+
+func TestSanitizeTagKey(t *testing.T) {
+	for _, test := range []struct {
+		key  string
+		want string
+	}{
+		{key: "env", want: "env"},
+		{key: "ENV", want: "env"},
+		{key: " env ", want: "env"},
+		{key: "cost-center", want: "cost_center"},
+		{key: "team.name", want: "team_name"},
+		{key: "a b", want: "a_b"},
+		{key: "app_1", want: "app_1"},
+		{key: "1app", want: "1app"},
+		{key: "région", want: "r_gion"},
+		{key: "", want: ""},
+	} {
+		t.Run(test.key, func(t *testing.T) {
+			if got := sanitizeTagKey(test.key); got != test.want {
+				t.Errorf("got %q; want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTagLabels(t *testing.T) {
+	// Duplicate keys (before or after sanitising) and empty keys are dropped
+	tags := NewTagLabels([]string{"env", "Team", "ENV", " ", "cost-center", "cost_center"})
+	if got, want := tags.Names(), []string{"tag_env", "tag_team", "tag_cost_center"}; !slices.Equal(got, want) {
+		t.Fatalf("got names %v; want %v", got, want)
+	}
+
+	for _, test := range []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{
+			name: "none",
+			tags: nil,
+			want: []string{"", "", ""},
+		},
+		{
+			name: "separators",
+			tags: []string{"env:prod", "team=sre", "Cost-Center:1234"},
+			want: []string{"prod", "sre", "1234"},
+		},
+		{
+			name: "repeated keys",
+			tags: []string{"env:prod", "env=dev", "ENV:staging"},
+			want: []string{"dev,prod,staging", "", ""},
+		},
+		{
+			name: "not allow-listed",
+			tags: []string{"web", "owner:alice", "env:prod"},
+			want: []string{"prod", "", ""},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := tags.Values(test.tags); !slices.Equal(got, test.want) {
+				t.Errorf("got %q; want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTagLabelsNil(t *testing.T) {
+	var tags *TagLabels
+	if got := tags.Names(); got != nil {
+		t.Errorf("got names %v; want nil", got)
+	}
+	if got := tags.Values([]string{"env:prod"}); got != nil {
+		t.Errorf("got values %v; want nil", got)
+	}
+}
+
+func TestCollectTagInfo(t *testing.T) {
+	// Every tag is reported verbatim, whether or not it is key:value or allow-listed
+	want := []string{"web", "env:prod", "Cost-Center=1234"}
+
+	ch := make(chan prometheus.Metric, len(want))
+	collectTagInfo(ch, newTagInfoDesc("instance"), "123", want)
+	close(ch)
+
+	var got []string
+	for m := range ch {
+		metric := &dto.Metric{}
+		if err := m.Write(metric); err != nil {
+			t.Fatal(err)
+		}
+		labels := map[string]string{}
+		for _, l := range metric.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["id"] != "123" || metric.GetGauge().GetValue() != 1 {
+			t.Errorf("got %v; want id=123 and value 1", metric)
+		}
+		got = append(got, labels["tag"])
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got tags %q; want %q", got, want)
+	}
+}
@@ -3,6 +3,8 @@ package collector
 import (
 	"context"
 	"log"
+	"strconv"
+	"time"
 
 	"github.com/linode/linodego"
 	"github.com/prometheus/client_golang/prometheus"
@@ -10,16 +12,21 @@ import (
 
 // TicketCollector represents a Linode Support Ticket
 type TicketCollector struct {
-	client linodego.Client
+	client TicketClient
 
-	Count *prometheus.Desc
+	Count          *prometheus.Desc
+	ByEntity       *prometheus.Desc
+	OldestOpenAge  *prometheus.Desc
+	LastUpdatedAge *prometheus.Desc
+	AwaitingReply  *prometheus.Desc
 }
 
 // NewTicketCollector creates a TicketCollector
-func NewTicketCollector(client linodego.Client) *TicketCollector {
+func NewTicketCollector(client TicketClient) *TicketCollector {
 	log.Println("[NewTicketCollector] Entered")
 	subsystem := "tickets"
 	labelKeys := []string{"status"}
+	ticketLabelKeys := []string{"id", "entity_type", "entity_id"}
 	return &TicketCollector{
 		client: client,
 
@@ -29,13 +36,41 @@ func NewTicketCollector(client linodego.Client) *TicketCollector {
 			labelKeys,
 			nil,
 		),
+		ByEntity: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "by_entity"),
+			"Number of support tickets by the type of entity (linode, volume, domain, nodebalancer etc.) referenced",
+			append(labelKeys, "entity_type"),
+			nil,
+		),
+		OldestOpenAge: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "oldest_open_age_seconds"),
+			"Age of the oldest open support ticket",
+			nil,
+			nil,
+		),
+		LastUpdatedAge: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "last_updated_age_seconds"),
+			"Time since an open support ticket was last updated",
+			ticketLabelKeys,
+			nil,
+		),
+		AwaitingReply: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "awaiting_reply"),
+			"Whether an open support ticket was last updated by Linode and is awaiting a reply from the account (1) or not (0)",
+			ticketLabelKeys,
+			nil,
+		),
 	}
 }
 
 // Collect implements Collector interface and is called by Prometheus to collect metrics
 func (c *TicketCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector interface and collects metrics until ctx is done
+func (c *TicketCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
 	log.Println("[TicketCollector:Collect] Entered")
-	ctx := context.Background()
 
 	tickets, err := c.client.ListTickets(ctx, nil)
 	if err != nil {
@@ -43,12 +78,61 @@ func (c *TicketCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
+	// Tickets last updated by someone other than the account's users are awaiting our reply
+	// If the account's users cannot be listed, awaiting reply is not reported
+	users, err := c.usernames(ctx)
+	if err != nil {
+		log.Println(err)
+	}
+
+	now := time.Now()
+	var oldest *time.Time
+
 	total := make(map[linodego.TicketStatus]float64)
+	type entityKey struct {
+		status     linodego.TicketStatus
+		entityType string
+	}
+	entities := make(map[entityKey]float64)
 	for _, t := range tickets {
 		total[t.Status]++
+
+		entityType, entityID := ticketEntity(t)
+		entities[entityKey{t.Status, entityType}]++
+
+		if t.Status == linodego.TicketClosed {
+			continue
+		}
+
+		if t.Opened != nil && (oldest == nil || t.Opened.Before(*oldest)) {
+			oldest = t.Opened
+		}
+
+		labelValues := []string{
+			strconv.Itoa(t.ID),
+			entityType,
+			entityID,
+		}
+		if t.Updated != nil {
+			ch <- newConstMetric(
+				c.LastUpdatedAge,
+				prometheus.GaugeValue,
+				now.Sub(*t.Updated).Seconds(),
+				labelValues...,
+			)
+		}
+		if users != nil {
+			_, ours := users[t.UpdatedBy]
+			ch <- newConstMetric(
+				c.AwaitingReply,
+				prometheus.GaugeValue,
+				boolToFloat64(!ours),
+				labelValues...,
+			)
+		}
 	}
 	for status, count := range total {
-		ch <- prometheus.MustNewConstMetric(
+		ch <- newConstMetric(
 			c.Count,
 			prometheus.GaugeValue,
 			count,
@@ -57,6 +141,21 @@ func (c *TicketCollector) Collect(ch chan<- prometheus.Metric) {
 		//[]string{status.String()}...,
 		)
 	}
+	for key, count := range entities {
+		ch <- newConstMetric(
+			c.ByEntity,
+			prometheus.GaugeValue,
+			count,
+			string(key.status), key.entityType,
+		)
+	}
+	if oldest != nil {
+		ch <- newConstMetric(
+			c.OldestOpenAge,
+			prometheus.GaugeValue,
+			now.Sub(*oldest).Seconds(),
+		)
+	}
 	log.Println("[TicketCollector:Collect] Completes")
 }
 
@@ -64,5 +163,30 @@ func (c *TicketCollector) Collect(ch chan<- prometheus.Metric) {
 func (c *TicketCollector) Describe(ch chan<- *prometheus.Desc) {
 	log.Println("[TicketCollector:Describe] Entered")
 	ch <- c.Count
+	ch <- c.ByEntity
+	ch <- c.OldestOpenAge
+	ch <- c.LastUpdatedAge
+	ch <- c.AwaitingReply
 	log.Println("[TicketCollector:Describe] Completes")
 }
+
+// usernames returns the set of the account's usernames
+func (c *TicketCollector) usernames(ctx context.Context) (map[string]struct{}, error) {
+	users, err := c.client.ListUsers(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	usernames := make(map[string]struct{}, len(users))
+	for _, u := range users {
+		usernames[u.Username] = struct{}{}
+	}
+	return usernames, nil
+}
+
+// ticketEntity returns the type and ID of the entity referenced by a ticket ("none" and "" if there is none)
+func ticketEntity(t linodego.Ticket) (string, string) {
+	if t.Entity == nil {
+		return "none", ""
+	}
+	return t.Entity.Type, strconv.Itoa(t.Entity.ID)
+}
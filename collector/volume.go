@@ -12,33 +12,42 @@ import (
 
 // VolumeCollector represents a Linode Volume
 type VolumeCollector struct {
-	client linodego.Client
+	inventory *Inventory
+	tags      *TagLabels
 
-	Up *prometheus.Desc
+	Up   *prometheus.Desc
+	Tags *prometheus.Desc
 }
 
 // NewVolumeCollector creates a new VolumeCollector
-func NewVolumeCollector(client linodego.Client) *VolumeCollector {
+// tags may be nil if no tags are to be mapped onto labels
+func NewVolumeCollector(inventory *Inventory, tags *TagLabels) *VolumeCollector {
 	log.Println("[VolumeCollector] Entered")
 	subsystem := "volume"
 	return &VolumeCollector{
-		client: client,
+		inventory: inventory,
+		tags:      tags,
 
 		Up: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, subsystem, "up"),
 			"Status of Volume",
-			[]string{"id", "label", "status", "region"},
+			append([]string{"id", "label", "status", "region"}, tags.Names()...),
 			nil,
 		),
+		Tags: newTagInfoDesc(subsystem),
 	}
 }
 
 // Collect implements Collector interface and is called by Prometheus to collect metrics
 func (c *VolumeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector interface and collects metrics until ctx is done
+func (c *VolumeCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
 	log.Println("[VolumeCollector:Collect] Entered")
-	ctx := context.Background()
 
-	volumes, err := c.client.ListVolumes(ctx, nil)
+	volumes, err := c.inventory.Volumes(ctx)
 	if err != nil {
 		log.Println(err)
 		return
@@ -50,13 +59,17 @@ func (c *VolumeCollector) Collect(ch chan<- prometheus.Metric) {
 		wg.Add(1)
 		go func(v linodego.Volume) {
 			defer wg.Done()
-			ch <- prometheus.MustNewConstMetric(
+			defer recoverPanic(ch, c.Up)
+			labelValues := []string{
+				strconv.Itoa(v.ID), v.Label, string(v.Status), v.Region,
+			}
+			ch <- newConstMetric(
 				c.Up,
 				prometheus.CounterValue,
 				1.0,
-				//Label Values
-				strconv.Itoa(v.ID), v.Label, string(v.Status), v.Region,
+				append(labelValues, c.tags.Values(v.Tags)...)...,
 			)
+			collectTagInfo(ch, c.Tags, labelValues[0], v.Tags)
 		}(volume)
 	}
 	wg.Wait()
@@ -67,5 +80,6 @@ func (c *VolumeCollector) Collect(ch chan<- prometheus.Metric) {
 func (c *VolumeCollector) Describe(ch chan<- *prometheus.Desc) {
 	log.Println("[VolumeCollector:Describe] Entered")
 	ch <- c.Up
+	ch <- c.Tags
 	log.Println("[VolumeCollector:Describe] Completes")
 }
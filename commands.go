@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DazWilkin/linode-exporter/collector"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	commandScrape    = "scrape"
+	commandInventory = "inventory"
+
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// usage describes the subcommands and the flags
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, `Usage: %[1]s [command] [flags]
+
+Commands:
+  (none)      Serve metrics (and service discovery) over HTTP
+  scrape      Print the metrics (Prometheus text format) once and exit
+  inventory   Print the Linodes, Volumes, NodeBalancers, LKE clusters and buckets (--format=table|json|csv) and exit
+
+Flags:
+`, os.Args[0])
+	flag.PrintDefaults()
+}
+
+// scrape writes the metrics gathered by gatherer to w in the Prometheus text format
+// Metrics that are gathered are written even if gathering others fails
+func scrape(gatherer prometheus.Gatherer, w io.Writer) error {
+	families, gatherErr := gatherer.Gather()
+	encoder := expfmt.NewEncoder(w, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, f := range families {
+		if err := encoder.Encode(f); err != nil {
+			return err
+		}
+	}
+	return gatherErr
+}
+
+// resource is a row of the inventory
+type resource struct {
+	Type   string   `json:"type"`
+	ID     string   `json:"id"`
+	Label  string   `json:"label"`
+	Region string   `json:"region"`
+	Status string   `json:"status,omitempty"`
+	Tags   []string `json:"tags"`
+}
+
+// listResources lists the (filtered) resources of the inventory
+// Types are named as they are by linode_resources_created_total
+func listResources(ctx context.Context, inventory *collector.Inventory) ([]resource, error) {
+	var resources []resource
+
+	instances, err := inventory.Instances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range instances {
+		resources = append(resources, resource{"linode", strconv.Itoa(i.ID), i.Label, i.Region, string(i.Status), i.Tags})
+	}
+
+	volumes, err := inventory.Volumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range volumes {
+		resources = append(resources, resource{"volume", strconv.Itoa(v.ID), v.Label, v.Region, string(v.Status), v.Tags})
+	}
+
+	nodebalancers, err := inventory.NodeBalancers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, nb := range nodebalancers {
+		label := ""
+		if nb.Label != nil {
+			label = *nb.Label
+		}
+		resources = append(resources, resource{"nodebalancer", strconv.Itoa(nb.ID), label, nb.Region, "", nb.Tags})
+	}
+
+	clusters, err := inventory.LKEClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range clusters {
+		resources = append(resources, resource{"lke_cluster", strconv.Itoa(c.ID), c.Label, c.Region, string(c.Status), c.Tags})
+	}
+
+	buckets, err := inventory.Buckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range buckets {
+		resources = append(resources, resource{"bucket", b.Region + "/" + b.Label, b.Label, b.Region, "", nil})
+	}
+
+	for i := range resources {
+		if resources[i].Tags == nil {
+			resources[i].Tags = []string{}
+		}
+	}
+	return resources, nil
+}
+
+// printInventory writes the (filtered) resources of the inventory to w in format (table, json or csv)
+func printInventory(ctx context.Context, inventory *collector.Inventory, format string, w io.Writer) error {
+	if format != formatTable && format != formatJSON && format != formatCSV {
+		return fmt.Errorf("unknown format %q; expected %s, %s or %s", format, formatTable, formatJSON, formatCSV)
+	}
+	resources, err := listResources(ctx, inventory)
+	if err != nil {
+		return err
+	}
+
+	row := func(r resource) []string {
+		return []string{r.Type, r.ID, r.Label, r.Region, r.Status, strings.Join(r.Tags, ",")}
+	}
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(resources)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"type", "id", "label", "region", "status", "tags"})
+		for _, r := range resources {
+			cw.Write(row(r))
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TYPE\tID\tLABEL\tREGION\tSTATUS\tTAGS")
+		for _, r := range resources {
+			fmt.Fprintln(tw, strings.Join(row(r), "\t"))
+		}
+		return tw.Flush()
+	}
+}
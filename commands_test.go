@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/linode-exporter/collector"
+	"github.com/DazWilkin/linode-exporter/linodefake"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestPrintInventory(t *testing.T) {
+	server := linodefake.NewServer(linodefake.Fixtures())
+	defer server.Close()
+	inventory := collector.NewInventory(server.Client(), nil, time.Minute)
+
+	// 4 Linodes, 1 Volume, 2 NodeBalancers, 1 LKE cluster and 1 bucket
+	const want = 9
+
+	var b bytes.Buffer
+	if err := printInventory(t.Context(), inventory, formatJSON, &b); err != nil {
+		t.Fatal(err)
+	}
+	var resources []resource
+	if err := json.Unmarshal(b.Bytes(), &resources); err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != want {
+		t.Errorf("got %d resources; want %d", len(resources), want)
+	}
+	if got := resources[0]; got.Type != "linode" || got.ID != "123" || got.Label != "web-1" || strings.Join(got.Tags, ",") != "env:prod,web" {
+		t.Errorf("got %+v; want web-1 (123)", got)
+	}
+
+	b.Reset()
+	if err := printInventory(t.Context(), inventory, formatCSV, &b); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The header and a record per resource
+	if len(records) != want+1 {
+		t.Errorf("got %d records; want %d", len(records), want+1)
+	}
+	if got := records[len(records)-1]; got[0] != "bucket" || got[1] != "us-east/assets" {
+		t.Errorf("got %v; want the assets bucket", got)
+	}
+
+	b.Reset()
+	if err := printInventory(t.Context(), inventory, formatTable, &b); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(b.String(), "\n"); got != want+1 {
+		t.Errorf("got %d lines; want %d", got, want+1)
+	}
+
+	if err := printInventory(t.Context(), inventory, "xml", &b); err == nil {
+		t.Error("got nil error for an unknown format")
+	}
+}
+
+func TestScrape(t *testing.T) {
+	server := linodefake.NewServer(linodefake.Fixtures())
+	defer server.Close()
+
+	// The collectors are created (from the flags' defaults) and gathered as by main
+	scraper, err := newScraper(server.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector.NewExporterCollector("", ""))
+
+	var b bytes.Buffer
+	if err := scrape(prometheus.Gatherers{scraper.Gatherer(timeout), registry}, &b); err != nil {
+		t.Fatal(err)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := func(name string) int {
+		return len(families[name].GetMetric())
+	}
+	for name, want := range map[string]int{
+		// 4 Linodes, 1 Volume, 2 NodeBalancers and 1 LKE cluster
+		"linode_instance_up":     4,
+		"linode_volume_up":       1,
+		"linode_nodebalancer_up": 2,
+		"linode_kubernetes_up":   1,
+		"linode_account_balance": 1,
+		"linode_exporter_info":   1,
+		// Every collector reports its success
+		"linode_exporter_collector_success": len(collector.Catalogue()),
+	} {
+		if got := count(name); got != want {
+			t.Errorf("got %d %s metrics; want %d", got, name, want)
+		}
+	}
+	for _, m := range families["linode_exporter_collector_success"].GetMetric() {
+		if m.GetGauge().GetValue() != 1 {
+			t.Errorf("got %v; want every collector to succeed", m)
+		}
+	}
+	if got := count("linode_exporter_collector_errors_total"); got != 0 {
+		t.Errorf("got %d collector errors; want none", got)
+	}
+}
@@ -0,0 +1,170 @@
+package linodefake
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/linode/linodego"
+)
+
+// request returns the status code and body of a request (method) for path from s
+func request(t *testing.T, s *Server, method, path string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), method, s.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := s.Server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestServerPagination(t *testing.T) {
+	s := NewServer(fstest.MapFS{
+		"linode/instances.json": {Data: []byte(`[{"id":1},{"id":2}]`)},
+		"account.json":          {Data: []byte(`{"euuid":"E1"}`)},
+	})
+	defer s.Close()
+
+	for _, test := range []struct {
+		path string
+		want string
+	}{
+		// Arrays are served as a single page of results
+		{path: "/v4/linode/instances", want: `{"data":[{"id":1},{"id":2}],"page":1,"pages":1,"results":2}`},
+		{path: "/v4beta/linode/instances?page=1", want: `{"data":[{"id":1},{"id":2}],"page":1,"pages":1,"results":2}`},
+		// Other values are served unchanged
+		{path: "/v4/account", want: `{"euuid":"E1"}`},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			code, body := request(t, s, http.MethodGet, test.path)
+			if code != http.StatusOK || body != test.want {
+				t.Errorf("got (%d, %s); want (%d, %s)", code, body, http.StatusOK, test.want)
+			}
+		})
+	}
+
+	// linodego follows the page of results
+	instances, err := s.Client().ListInstances(t.Context(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 2 {
+		t.Errorf("got %d instances; want 2", len(instances))
+	}
+	if got := s.Requests("linode/instances"); got != 3 {
+		t.Errorf("got %d requests; want 3", got)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	s := NewServer(fstest.MapFS{
+		"linode/instances.json": {Data: []byte(`[{"id":1}]`)},
+		"volumes.json":          {Data: []byte(`[{"id":`)},
+	})
+	defer s.Close()
+
+	for _, test := range []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{name: "missing fixture", method: http.MethodGet, path: "/v4/domains", want: http.StatusNotFound},
+		{name: "malformed fixture", method: http.MethodGet, path: "/v4/volumes", want: http.StatusInternalServerError},
+		// The fake is read-only
+		{name: "method", method: http.MethodPost, path: "/v4/linode/instances", want: http.StatusMethodNotAllowed},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			code, body := request(t, s, test.method, test.path)
+			if code != test.want {
+				t.Errorf("got %d; want %d", code, test.want)
+			}
+			var e struct {
+				Errors []struct {
+					Reason string `json:"reason"`
+				} `json:"errors"`
+			}
+			if err := json.Unmarshal([]byte(body), &e); err != nil || len(e.Errors) != 1 {
+				t.Errorf("got %s (%v); want a Linode API error", body, err)
+			}
+		})
+	}
+}
+
+func TestServerOverrides(t *testing.T) {
+	s := NewServer(Fixtures())
+	defer s.Close()
+	client := s.Client()
+
+	// Set replaces a fixture; slices are served as a page of results
+	if err := s.Set("/linode/instances/", []linodego.Instance{{ID: 42, Label: "override"}}); err != nil {
+		t.Fatal(err)
+	}
+	instances, err := client.ListInstances(t.Context(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 1 || instances[0].ID != 42 || instances[0].Label != "override" {
+		t.Errorf("got %+v; want the override", instances)
+	}
+
+	// Set serves paths that have no fixture
+	if err := s.Set("linode/instances/42", linodego.Instance{ID: 42}); err != nil {
+		t.Fatal(err)
+	}
+	if instance, err := client.GetInstance(t.Context(), 42); err != nil || instance.ID != 42 {
+		t.Errorf("got (%+v, %v); want the override", instance, err)
+	}
+
+	// Fail serves a Linode API error in place of a fixture (or an override)
+	s.Fail("linode/instances", http.StatusTooManyRequests)
+	_, err = client.ListInstances(t.Context(), nil)
+	var e *linodego.Error
+	if !errors.As(err, &e) || e.Code != http.StatusTooManyRequests {
+		t.Errorf("got %v; want a %d error", err, http.StatusTooManyRequests)
+	}
+
+	// Other paths are served from their fixtures
+	if _, err := client.ListVolumes(t.Context(), nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServerEscaping(t *testing.T) {
+	s := NewServer(fstest.MapFS{
+		"tags/env%3Aprod.json": {Data: []byte(`[{"type":"linode","data":{"id":1}}]`)},
+	})
+	defer s.Close()
+
+	// Segments containing ':' are served from fixtures with ':' escaped, whether or not the request escapes it
+	for _, path := range []string{"/v4/tags/env:prod", "/v4/tags/env%3Aprod"} {
+		code, body := request(t, s, http.MethodGet, path)
+		if code != http.StatusOK || !strings.Contains(body, `"type":"linode"`) {
+			t.Errorf("%s: got (%d, %s); want the tag's fixture", path, code, body)
+		}
+	}
+
+	objects, err := s.Client().ListTaggedObjects(t.Context(), "env:prod", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Type != "linode" {
+		t.Errorf("got %+v; want one linode", objects)
+	}
+	if got := s.Requests("tags/env:prod"); got != 3 {
+		t.Errorf("got %d requests; want 3", got)
+	}
+}
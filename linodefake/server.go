@@ -0,0 +1,184 @@
+// Package linodefake provides a fake of the Linode v4 API for testing
+//
+// The fake serves JSON fixtures by path: a GET of /v4/linode/instances/123/stats is served from
+// linode/instances/123/stats.json. Fixtures that are JSON arrays are served as a single page of results.
+// Path segments containing ':' (e.g. tags of the form key:value) are stored with ':' escaped as %3A.
+// Filters (X-Filter) are not applied; every result is returned.
+package linodefake
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/linode/linodego"
+)
+
+var (
+	//go:embed fixtures
+	fixtures embed.FS
+
+	// version matches the API version prefix of a Linode API path, e.g. /v4 or /v4beta
+	version = regexp.MustCompile(`^/v\d+[a-z]*`)
+)
+
+// Fixtures returns the default fixtures: an account with (at least) one of each type of resource
+func Fixtures() fs.FS {
+	sub, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		// fixtures is embedded; this is a programming error
+		panic(err)
+	}
+	return sub
+}
+
+// response is a fixture that overrides the file system
+type response struct {
+	status int
+	body   []byte
+}
+
+// Server is a fake Linode v4 API
+type Server struct {
+	*httptest.Server
+	fixtures fs.FS
+
+	mu        sync.Mutex
+	overrides map[string]response
+	requests  map[string]int
+}
+
+// NewServer creates and starts a Server serving fixtures (e.g. Fixtures())
+// The Server should be closed when it is no longer needed
+func NewServer(fixtures fs.FS) *Server {
+	s := &Server{
+		fixtures:  fixtures,
+		overrides: map[string]response{},
+		requests:  map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Client returns a linodego Client for the Server
+func (s *Server) Client() *linodego.Client {
+	client := linodego.NewClient(s.Server.Client())
+	client.SetBaseURL(s.URL)
+	client.SetRetryCount(0)
+	return &client
+}
+
+// Set serves v (as JSON) for path (e.g. "linode/instances") in place of its fixture
+// Slices are served as a single page of results
+func (s *Server) Set(path string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[strings.Trim(path, "/")] = response{status: http.StatusOK, body: body}
+	return nil
+}
+
+// Fail serves a Linode API error with status for path (e.g. "linode/instances") in place of its fixture
+func (s *Server) Fail(path string, status int) {
+	body, _ := json.Marshal(map[string]any{
+		"errors": []map[string]string{
+			{"reason": http.StatusText(status)},
+		},
+	})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[strings.Trim(path, "/")] = response{status: status, body: body}
+}
+
+// Requests returns the number of requests made for path (e.g. "linode/instances")
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[strings.Trim(path, "/")]
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(version.ReplaceAllString(r.URL.Path, ""), "/")
+	log.Printf("[linodefake] %s %s", r.Method, path)
+
+	s.mu.Lock()
+	s.requests[path]++
+	override, ok := s.overrides[path]
+	s.mu.Unlock()
+
+	if r.Method != http.MethodGet {
+		s.fail(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	status, body := override.status, override.body
+	if !ok {
+		var err error
+		body, err = fs.ReadFile(s.fixtures, strings.ReplaceAll(path, ":", "%3A")+".json")
+		if errors.Is(err, fs.ErrNotExist) {
+			s.fail(w, http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("[linodefake] %s: %v", path, err)
+			s.fail(w, http.StatusInternalServerError)
+			return
+		}
+		status = http.StatusOK
+	}
+
+	if status == http.StatusOK {
+		var err error
+		body, err = paginate(body)
+		if err != nil {
+			log.Printf("[linodefake] %s: %v", path, err)
+			s.fail(w, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func (s *Server) fail(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"errors":[{"reason":%q}]}`, http.StatusText(status))
+}
+
+// paginate wraps a JSON array in a (single) page of results; other JSON values are unchanged
+func paginate(body []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return body, nil
+	}
+	var data []json.RawMessage
+	if err := json.Unmarshal(trimmed, &data); err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Data    []json.RawMessage `json:"data"`
+		Page    int               `json:"page"`
+		Pages   int               `json:"pages"`
+		Results int               `json:"results"`
+	}{
+		Data:    data,
+		Page:    1,
+		Pages:   1,
+		Results: len(data),
+	})
+}
@@ -1,14 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
 	"text/template"
 	"time"
 
 	"github.com/DazWilkin/linode-exporter/collector"
+	"github.com/DazWilkin/linode-exporter/push"
+	"github.com/DazWilkin/linode-exporter/sd"
+	"github.com/DazWilkin/linode-exporter/transport"
 
 	"github.com/linode/linodego"
 
@@ -20,6 +31,8 @@ import (
 
 const (
 	timeout = 60 * time.Second
+	// shutdownTimeout bounds stopping the server and flushing pushed metrics
+	shutdownTimeout = 10 * time.Second
 )
 
 var (
@@ -34,6 +47,57 @@ var (
 	debug       = flag.Bool("debug", false, "Enable Linode REST API debugging")
 	endpoint    = flag.String("endpoint", ":9388", "The endpoint of the HTTP server")
 	metricsPath = flag.String("path", "/metrics", "The path on which Prometheus metrics will be served")
+
+	objProbe     = flag.Bool("objectstorage_probe", false, "Probe Object Storage buckets through the S3 API (requires access keys)")
+	objAccessKey = flag.String("objectstorage_access_key", os.Getenv("LINODE_OBJ_ACCESS_KEY"), "Object Storage access key used to probe buckets")
+	objSecretKey = flag.String("objectstorage_secret_key", os.Getenv("LINODE_OBJ_SECRET_KEY"), "Object Storage secret key used to probe buckets")
+	objEndpoint  = flag.String("objectstorage_endpoint", "", "Override buckets' hostnames with this (path-style) S3 endpoint, e.g. a local S3-compatible server")
+
+	tagLabels = flag.String("tag_labels", "", "Comma-separated allow-list of tag keys (from key:value or key=value tags) to add as tag_{key} labels")
+
+	includeTags    = flag.String("include_tags", "", "Comma-separated list of tags; only export resources with any of these tags")
+	excludeTags    = flag.String("exclude_tags", "", "Comma-separated list of tags; do not export resources with any of these tags")
+	includeRegions = flag.String("include_regions", "", "Comma-separated list of regions; only export resources in these regions")
+	excludeRegions = flag.String("exclude_regions", "", "Comma-separated list of regions; do not export resources in these regions")
+	includeLabel   = flag.String("include_label", "", "Regular expression; only export resources whose labels match")
+	excludeLabel   = flag.String("exclude_label", "", "Regular expression; do not export resources whose labels match")
+	includeIDs     = flag.String("include_ids", "", "Comma-separated list of IDs; only export resources with these IDs")
+	excludeIDs     = flag.String("exclude_ids", "", "Comma-separated list of IDs; do not export resources with these IDs")
+
+	maxConcurrency          = flag.Int("max_concurrency", 20, "Maximum number of concurrent per-resource Linode API requests across all collectors (0 is unlimited)")
+	maxCollectorConcurrency = flag.Int("max_collector_concurrency", 5, "Maximum number of concurrent per-resource Linode API requests per collector (0 is unlimited)")
+
+	maxRetries   = flag.Int("max_retries", 3, "Maximum number of times a Linode API request is retried after a 429 or 5xx response")
+	minRemaining = flag.Int("ratelimit_min_remaining", 50, "Low-priority collectors (stats, tags) are skipped when fewer Linode API requests than this remain in the rate-limit window")
+
+	collectorTimeout  = flag.Duration("collector_timeout", 50*time.Second, "Maximum duration of each collector's collection (0 is unlimited)")
+	collectorTimeouts = flag.String("collector_timeouts", "", "Comma-separated list of collector=duration overrides of --collector_timeout, e.g. instance_stats=20s")
+
+	recordDir = flag.String("record.dir", "", "Record (sanitised) Linode API requests and responses to this directory")
+	replayDir = flag.String("replay.dir", "", "Serve Linode API requests from recordings in this directory (--record.dir) rather than the Linode API")
+
+	sdAddress = flag.String("sd.address", string(sd.PublicIPv4), "Address of Linodes (and LKE nodes) used as service discovery targets: public_ipv4, private_ipv4 or public_ipv6")
+	sdPort    = flag.Int("sd.port", 9100, "Port of Linodes' (and LKE nodes') service discovery targets, e.g. node_exporter's")
+
+	otlpEndpoint = flag.String("otlp.endpoint", "", "Export metrics to this OpenTelemetry (OTLP) receiver: host:port or URL, e.g. localhost:4317 or http://localhost:4318/v1/metrics")
+	otlpProtocol = flag.String("otlp.protocol", push.ProtocolGRPC, "OTLP protocol: grpc or http (protobuf)")
+	otlpInsecure = flag.Bool("otlp.insecure", false, "Disable TLS for OTLP endpoints without a scheme")
+	otlpHeaders  = flag.String("otlp.headers", "", "Comma-separated list of key=value headers sent with each OTLP export, e.g. authorization=Bearer ...")
+	otlpInterval = flag.Duration("otlp.interval", 60*time.Second, "Duration between OTLP exports")
+
+	remoteWriteURL            = flag.String("remote-write.url", "", "Push metrics to this Prometheus remote-write endpoint, e.g. http://localhost:9090/api/v1/write")
+	remoteWriteInterval       = flag.Duration("remote-write.interval", 60*time.Second, "Duration between remote-write pushes")
+	remoteWriteTimeout        = flag.Duration("remote-write.timeout", 30*time.Second, "Maximum duration of each remote-write request")
+	remoteWriteMaxRetries     = flag.Int("remote-write.max-retries", 3, "Maximum number of times a remote-write request is retried after a network error, 429 or 5xx response")
+	remoteWriteQueueCapacity  = flag.Int("remote-write.queue-capacity", 10, "Maximum number of pushes queued while the remote-write endpoint is slow or failing")
+	remoteWriteUsername       = flag.String("remote-write.username", "", "Username for remote-write basic authentication")
+	remoteWritePassword       = flag.String("remote-write.password", os.Getenv("REMOTE_WRITE_PASSWORD"), "Password for remote-write basic authentication")
+	remoteWriteBearerToken    = flag.String("remote-write.bearer-token", os.Getenv("REMOTE_WRITE_BEARER_TOKEN"), "Bearer token for remote-write authentication")
+	remoteWriteExternalLabels = flag.String("remote-write.external-labels", "", "Comma-separated list of key=value labels added to every pushed series, e.g. site=edge-1")
+
+	format = flag.String("format", formatTable, "Format of the inventory subcommand's output: table, json or csv")
+
+	inventoryTTL = flag.Duration("inventory_ttl", 30*time.Second, "Duration for which resource lists are shared between collectors before being refreshed")
 )
 
 const (
@@ -45,6 +109,9 @@ const (
 	<body>
 		<h1>Linode Exporter</h1>
 		<p><a href="{{.MetricsPath}}">Metrics</a></p>
+		<p><a href="/sd/instances">Service discovery: Linodes</a></p>
+		<p><a href="/sd/lke">Service discovery: LKE nodes</a></p>
+		<p><a href="/sd/nodebalancers">Service discovery: NodeBalancers' nodes</a></p>
 	</body>
 </html>`
 )
@@ -63,48 +130,339 @@ func rootHandler(w http.ResponseWriter, r *http.Request) {
 		log.Printf("[rootHandler] error executing template: %v", err)
 	}
 }
-func main() {
-	flag.Parse()
-	if *token == "" {
-		log.Fatal("Provide Linode API Token")
+
+// split splits a comma-separated flag value, returning nil for the empty string
+func split(s string) []string {
+	if s == "" {
+		return nil
 	}
+	return strings.Split(s, ",")
+}
 
-	if GitCommit == "" {
-		log.Println("[main] GitCommit value unset (\"\"); expected to be set during build")
+// newFilter creates a collector.Filter from the include|exclude flags
+func newFilter() (*collector.Filter, error) {
+	filter := &collector.Filter{
+		IncludeTags:    split(*includeTags),
+		ExcludeTags:    split(*excludeTags),
+		IncludeRegions: split(*includeRegions),
+		ExcludeRegions: split(*excludeRegions),
 	}
-	if OSVersion == "" {
-		log.Println("[main] OSVersion value (\"\"); expected to be set during build")
+	var err error
+	if *includeLabel != "" {
+		if filter.IncludeLabel, err = regexp.Compile(*includeLabel); err != nil {
+			return nil, err
+		}
+	}
+	if *excludeLabel != "" {
+		if filter.ExcludeLabel, err = regexp.Compile(*excludeLabel); err != nil {
+			return nil, err
+		}
+	}
+	if filter.IncludeIDs, err = collector.ParseIDs(split(*includeIDs)); err != nil {
+		return nil, err
 	}
+	if filter.ExcludeIDs, err = collector.ParseIDs(split(*excludeIDs)); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// parseTimeouts parses a comma-separated list of collector=duration pairs
+func parseTimeouts(s string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for _, pair := range split(s) {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected collector=duration, got %q", pair)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		timeouts[name] = d
+	}
+	return timeouts, nil
+}
+
+// parsePairs parses a comma-separated list of key=value pairs
+func parsePairs(s string) (map[string]string, error) {
+	pairs := map[string]string{}
+	for _, pair := range split(s) {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		pairs[key] = value
+	}
+	return pairs, nil
+}
+
+// newOTLP creates an OTLP exporter of the metrics gathered by gatherer
+// The exported metrics' resource attributes identify the exporter's version and (if it can be retrieved) the Linode account
+func newOTLP(client *linodego.Client, gatherer prometheus.Gatherer) (*push.OTLP, error) {
+	headers, err := parsePairs(*otlpHeaders)
+	if err != nil {
+		return nil, err
+	}
+	attributes := map[string]string{
+		"service.version": GitCommit,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if account, err := client.GetAccount(ctx); err != nil {
+		log.Printf("[newOTLP] unable to get account: %v", err)
+	} else {
+		attributes["linode.account.euuid"] = account.EUUID
+	}
+	return push.NewOTLP(context.Background(), gatherer, push.OTLPOptions{
+		Endpoint:   *otlpEndpoint,
+		Protocol:   *otlpProtocol,
+		Insecure:   *otlpInsecure,
+		Headers:    headers,
+		Interval:   *otlpInterval,
+		Attributes: attributes,
+	})
+}
+
+// newRemoteWrite creates a remote-write of the metrics gathered by gatherer
+func newRemoteWrite(gatherer prometheus.Gatherer) (*push.RemoteWrite, error) {
+	externalLabels, err := parsePairs(*remoteWriteExternalLabels)
+	if err != nil {
+		return nil, err
+	}
+	return push.NewRemoteWrite(gatherer, push.RemoteWriteOptions{
+		URL:            *remoteWriteURL,
+		Interval:       *remoteWriteInterval,
+		Timeout:        *remoteWriteTimeout,
+		MaxRetries:     *remoteWriteMaxRetries,
+		QueueCapacity:  *remoteWriteQueueCapacity,
+		Username:       *remoteWriteUsername,
+		Password:       *remoteWritePassword,
+		BearerToken:    *remoteWriteBearerToken,
+		ExternalLabels: externalLabels,
+	})
+}
+
+// metricsHandler serves the registry's metrics and the scraper's metrics bound to the request's context
+// The context is also bounded by Prometheus' scrape timeout (X-Prometheus-Scrape-Timeout-Seconds)
+func metricsHandler(registry *prometheus.Registry, scraper *collector.Scraper) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); v != "" {
+			if seconds, err := strconv.ParseFloat(v, 64); err == nil && seconds > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(seconds*float64(time.Second)))
+				defer cancel()
+			}
+		}
+
+		// Only the collectors are bound to ctx so that, when it is done, their (partial) metrics are served rather than an error
+		scrape, err := scraper.Registry(ctx)
+		if err != nil {
+			log.Printf("[metricsHandler] unable to create registry: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Invalid metrics are logged and omitted rather than failing the scrape
+		// The Linode collectors are gathered first so that the API client's metrics include their requests
+		promhttp.HandlerFor(prometheus.Gatherers{scrape, registry}, promhttp.HandlerOpts{
+			ErrorLog:      log.Default(),
+			ErrorHandling: promhttp.ContinueOnError,
+			Timeout:       timeout,
+		}).ServeHTTP(w, r)
+	})
+}
 
+// newClient creates a Linode API client from the flags
+// Requests are instrumented, rate-limit aware (and retried) and, optionally, recorded or replayed
+func newClient() (*linodego.Client, *transport.Instrumented, *transport.RateLimit) {
 	source := oauth2.StaticTokenSource(&oauth2.Token{
 		AccessToken: *token,
 	})
+	// Each attempt (including retries) is instrumented (and recorded or replayed)
+	var base http.RoundTripper
+	switch {
+	case *recordDir != "":
+		base = transport.NewRecorder(nil, *recordDir)
+	case *replayDir != "":
+		base = transport.NewReplayer(*replayDir)
+	}
+	instrumented := transport.NewInstrumented(base)
+	ratelimit := transport.NewRateLimit(instrumented, *maxRetries, *minRemaining)
 	oauth2Client := &http.Client{
 		Transport: &oauth2.Transport{
 			Source: source,
+			Base:   ratelimit,
 		},
 	}
 	client := linodego.NewClient(oauth2Client)
 	client.SetDebug(*debug)
+	// Retries are handled by the (rate-limit aware) transport
+	client.SetRetryCount(0)
+	return &client, instrumented, ratelimit
+}
+
+// newScraper creates the Linode collectors from the flags
+func newScraper(client *linodego.Client, budget collector.Budget) (*collector.Scraper, error) {
+	filter, err := newFilter()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse filter: %w", err)
+	}
+
+	var probe *collector.S3Probe
+	if *objProbe {
+		if *objAccessKey == "" || *objSecretKey == "" {
+			return nil, errors.New("provide Object Storage access and secret keys to probe buckets")
+		}
+		probe = collector.NewS3Probe(*objAccessKey, *objSecretKey, *objEndpoint)
+	}
+
+	timeouts, err := parseTimeouts(*collectorTimeouts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse collector timeouts: %w", err)
+	}
+
+	return collector.New(client, collector.Options{
+		TagLabels:               split(*tagLabels),
+		Filter:                  filter,
+		InventoryTTL:            *inventoryTTL,
+		MaxConcurrency:          *maxConcurrency,
+		MaxCollectorConcurrency: *maxCollectorConcurrency,
+		Budget:                  budget,
+		Probe:                   probe,
+		Timeout:                 *collectorTimeout,
+		Timeouts:                timeouts,
+	})
+}
+
+func main() {
+	// The first argument may be a subcommand; without one, the exporter serves metrics
+	command, args := "", os.Args[1:]
+	if len(args) > 0 && (args[0] == commandScrape || args[0] == commandInventory) {
+		command, args = args[0], args[1:]
+	}
+	flag.Usage = usage
+	flag.CommandLine.Parse(args)
 
+	if *recordDir != "" && *replayDir != "" {
+		log.Fatal("Provide either --record.dir or --replay.dir, not both")
+	}
+	// Recordings are replayed without the Linode API and so without a token
+	if *token == "" && *replayDir == "" {
+		log.Fatal("Provide Linode API Token")
+	}
+
+	if GitCommit == "" {
+		log.Println("[main] GitCommit value unset (\"\"); expected to be set during build")
+	}
+	if OSVersion == "" {
+		log.Println("[main] OSVersion value (\"\"); expected to be set during build")
+	}
+
+	client, instrumented, ratelimit := newClient()
+
+	// The Linode collectors are bound to each scrape's context
+	scraper, err := newScraper(client, ratelimit)
+	if err != nil {
+		log.Fatalf("Unable to create collectors: %v", err)
+	}
+
+	// The exporter's own collectors are registered once
 	registry := prometheus.NewRegistry()
-	registry.MustRegister(collector.NewAccountCollector(client))
-	registry.MustRegister(collector.NewExporterCollector(client, OSVersion, GitCommit))
-	registry.MustRegister(collector.NewInstanceCollector(client))
-	registry.MustRegister(collector.NewInstanceStatsCollector(client))
-	registry.MustRegister(collector.NewKubernetesCollector(client))
-	registry.MustRegister(collector.NewNodeBalancerCollector(client))
-	registry.MustRegister(collector.NewTicketCollector(client))
-	registry.MustRegister(collector.NewVolumeCollector(client))
-	registry.MustRegister(collector.NewObjectStorageCollector(client))
+	registry.MustRegister(ratelimit)
+	registry.MustRegister(instrumented)
+	registry.MustRegister(collector.NewExporterCollector(OSVersion, GitCommit))
+
+	// Metrics may also be pushed (or printed); they are gathered as if scraped
+	// The Linode collectors are gathered first so that the API client's metrics include their requests
+	gatherer := prometheus.Gatherers{scraper.Gatherer(timeout), registry}
+
+	switch command {
+	case commandScrape:
+		if err := scrape(gatherer, os.Stdout); err != nil {
+			log.Fatalf("Unable to scrape: %v", err)
+		}
+		return
+	case commandInventory:
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := printInventory(ctx, scraper.Inventory(), *format, os.Stdout); err != nil {
+			log.Fatalf("Unable to print inventory: %v", err)
+		}
+		return
+	}
+
+	address, err := sd.ParseAddress(*sdAddress)
+	if err != nil {
+		log.Fatalf("Unable to parse service discovery address: %v", err)
+	}
+
+	// The server and the pushes stop on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var otlp *push.OTLP
+	if *otlpEndpoint != "" {
+		otlp, err = newOTLP(client, gatherer)
+		if err != nil {
+			log.Fatalf("Unable to create OTLP exporter: %v", err)
+		}
+		log.Printf("[main] metrics exported (OTLP) to: %s", *otlpEndpoint)
+	}
+	remoteWriteDone := make(chan struct{})
+	if *remoteWriteURL != "" {
+		remoteWrite, err := newRemoteWrite(gatherer)
+		if err != nil {
+			log.Fatalf("Unable to create remote-write: %v", err)
+		}
+		// The remote-write's own metrics are pushed too
+		registry.MustRegister(remoteWrite)
+		go func() {
+			defer close(remoteWriteDone)
+			remoteWrite.Run(ctx)
+		}()
+		log.Printf("[main] metrics pushed (remote-write) to: %s", *remoteWriteURL)
+	} else {
+		close(remoteWriteDone)
+	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/", http.HandlerFunc(rootHandler))
-	mux.Handle(*metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{
-		Timeout: timeout,
-	}))
+	mux.Handle(*metricsPath, metricsHandler(registry, scraper))
+	// Service discovery shares the collectors' (filtered) inventory
+	mux.Handle("/sd/instances", sd.NewInstancesHandler(scraper.Inventory(), address, *sdPort))
+	mux.Handle("/sd/lke", sd.NewLKEHandler(scraper.Inventory(), client, address, *sdPort))
+	mux.Handle("/sd/nodebalancers", sd.NewNodeBalancersHandler(scraper.Inventory(), client))
 
 	log.Printf("[main] Server starting (%s)", *endpoint)
 	log.Printf("[main] metrics served on: %s", *metricsPath)
-	log.Fatal(http.ListenAndServe(*endpoint, mux))
+	log.Printf("[main] service discovery served on: /sd/instances, /sd/lke and /sd/nodebalancers")
+
+	server := &http.Server{
+		Addr:    *endpoint,
+		Handler: mux,
+	}
+	go func() {
+		<-ctx.Done()
+		log.Println("[main] Server stopping")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[main] unable to stop server: %v", err)
+		}
+	}()
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+
+	// Shutdown exports the OTLP exporter's final metrics
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if otlp != nil {
+		if err := otlp.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[main] unable to stop OTLP exporter: %v", err)
+		}
+	}
+	<-remoteWriteDone
+	log.Println("[main] Server stopped")
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/linode-exporter/collector"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// blockingCollector is a ContextCollector that blocks until its context is done
+type blockingCollector struct {
+	desc *prometheus.Desc
+}
+
+func (c *blockingCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+func (c *blockingCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	<-ctx.Done()
+}
+
+func (c *blockingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func TestMetricsHandlerScrapeTimeout(t *testing.T) {
+	scraper := collector.NewScraper(map[string]collector.ContextCollector{
+		"blocking": &blockingCollector{
+			desc: prometheus.NewDesc("linode_test_blocking", "Test metric", nil, nil),
+		},
+	}, 0, nil)
+	handler := metricsHandler(prometheus.NewRegistry(), scraper)
+
+	for _, test := range []struct {
+		name    string
+		header  string
+		timeout time.Duration
+		// want is the response's collector success, if it responds before timeout
+		want string
+	}{
+		{
+			name:    "scrape timeout",
+			header:  "0.05",
+			timeout: time.Second,
+			want:    `linode_exporter_collector_success{collector="blocking"} 0`,
+		},
+		{
+			// Malformed (and non-positive) timeouts are ignored; the collector is bound only to the request
+			name:    "malformed",
+			header:  "soon",
+			timeout: 100 * time.Millisecond,
+		},
+		{
+			name:    "zero",
+			header:  "0",
+			timeout: 100 * time.Millisecond,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(t.Context(), test.timeout)
+			defer cancel()
+			req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/metrics", nil)
+			req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", test.header)
+
+			start := time.Now()
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			elapsed := time.Since(start)
+
+			if test.want == "" {
+				// The scrape ends only when the request does
+				if elapsed < test.timeout {
+					t.Errorf("got a response after %s; want none before the request is cancelled (%s)", elapsed, test.timeout)
+				}
+				return
+			}
+			if elapsed >= test.timeout {
+				t.Errorf("got a response after %s; want it within the scrape timeout", elapsed)
+			}
+			if w.Code != http.StatusOK {
+				t.Errorf("got %d; want %d", w.Code, http.StatusOK)
+			}
+			if !strings.Contains(w.Body.String(), test.want) {
+				t.Errorf("got %q; want %s", w.Body.String(), test.want)
+			}
+		})
+	}
+}
+
+// panickingCollector is a ContextCollector that sends an invalid metric and then panics
+type panickingCollector struct {
+	desc *prometheus.Desc
+}
+
+func (c *panickingCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+func (c *panickingCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	ch <- prometheus.NewInvalidMetric(c.desc, errors.New("invalid"))
+	panic("boom")
+}
+
+func (c *panickingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func TestMetricsHandlerErrors(t *testing.T) {
+	balance := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "linode_account_balance",
+		Help: "Balance of account",
+	})
+	balance.Set(42)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(balance)
+
+	scraper := collector.NewScraper(map[string]collector.ContextCollector{
+		"panicking": &panickingCollector{
+			desc: prometheus.NewDesc("linode_test_panicking", "Test metric", nil, nil),
+		},
+	}, time.Minute, nil)
+
+	w := httptest.NewRecorder()
+	metricsHandler(registry, scraper).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// The scrape succeeds with the remaining metrics
+	if w.Code != http.StatusOK {
+		t.Errorf("got %d; want %d", w.Code, http.StatusOK)
+	}
+	for _, want := range []string{
+		"linode_account_balance 42",
+		`linode_exporter_collector_success{collector="panicking"} 0`,
+		`linode_exporter_collector_errors_total{collector="panicking",reason="panic"} 1`,
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("got %q; want %s", w.Body.String(), want)
+		}
+	}
+}
@@ -1,9 +1,23 @@
+// Package mock provides fakes of the (narrow) Linode API client interfaces used by the collectors
+//
+// The fakes (collector.go) are generated by moq from the interfaces in collector/client.go:
+//
+//	go generate ./collector
 package mock
 
-// Client is a mock implementation of linodego.Client
-type Client struct{}
+// InventoryClient is a fake collector.InventoryClient
+// Configure the embedded listers' funcs for the resources used
+type InventoryClient struct {
+	InstanceListerMock
+	VolumeListerMock
+	NodeBalancerListerMock
+	LKEClusterListerMock
+	ObjectStorageBucketListerMock
+	DomainListerMock
+}
 
-// NewClient is a mock implementation of linodego.Client constructor
-func NewClient() *Client {
-	return &Client{}
+// TicketClient is a fake collector.TicketClient
+type TicketClient struct {
+	TicketListerMock
+	UserListerMock
 }
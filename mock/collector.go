@@ -0,0 +1,1268 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/linode/linodego"
+	"sync"
+)
+
+// AccountGetterMock is a mock implementation of collector.AccountGetter.
+//
+//	func TestSomethingThatUsesAccountGetter(t *testing.T) {
+//
+//		// make and configure a mocked collector.AccountGetter
+//		mockedAccountGetter := &AccountGetterMock{
+//			GetAccountFunc: func(ctx context.Context) (*linodego.Account, error) {
+//				panic("mock out the GetAccount method")
+//			},
+//		}
+//
+//		// use mockedAccountGetter in code that requires collector.AccountGetter
+//		// and then make assertions.
+//
+//	}
+type AccountGetterMock struct {
+	// GetAccountFunc mocks the GetAccount method.
+	GetAccountFunc func(ctx context.Context) (*linodego.Account, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetAccount holds details about calls to the GetAccount method.
+		GetAccount []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockGetAccount sync.RWMutex
+}
+
+// GetAccount calls GetAccountFunc.
+func (mock *AccountGetterMock) GetAccount(ctx context.Context) (*linodego.Account, error) {
+	if mock.GetAccountFunc == nil {
+		panic("AccountGetterMock.GetAccountFunc: method is nil but AccountGetter.GetAccount was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetAccount.Lock()
+	mock.calls.GetAccount = append(mock.calls.GetAccount, callInfo)
+	mock.lockGetAccount.Unlock()
+	return mock.GetAccountFunc(ctx)
+}
+
+// GetAccountCalls gets all the calls that were made to GetAccount.
+// Check the length with:
+//
+//	len(mockedAccountGetter.GetAccountCalls())
+func (mock *AccountGetterMock) GetAccountCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetAccount.RLock()
+	calls = mock.calls.GetAccount
+	mock.lockGetAccount.RUnlock()
+	return calls
+}
+
+// InstanceListerMock is a mock implementation of collector.InstanceLister.
+//
+//	func TestSomethingThatUsesInstanceLister(t *testing.T) {
+//
+//		// make and configure a mocked collector.InstanceLister
+//		mockedInstanceLister := &InstanceListerMock{
+//			ListInstancesFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Instance, error) {
+//				panic("mock out the ListInstances method")
+//			},
+//		}
+//
+//		// use mockedInstanceLister in code that requires collector.InstanceLister
+//		// and then make assertions.
+//
+//	}
+type InstanceListerMock struct {
+	// ListInstancesFunc mocks the ListInstances method.
+	ListInstancesFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Instance, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListInstances holds details about calls to the ListInstances method.
+		ListInstances []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+	}
+	lockListInstances sync.RWMutex
+}
+
+// ListInstances calls ListInstancesFunc.
+func (mock *InstanceListerMock) ListInstances(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Instance, error) {
+	if mock.ListInstancesFunc == nil {
+		panic("InstanceListerMock.ListInstancesFunc: method is nil but InstanceLister.ListInstances was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListInstances.Lock()
+	mock.calls.ListInstances = append(mock.calls.ListInstances, callInfo)
+	mock.lockListInstances.Unlock()
+	return mock.ListInstancesFunc(ctx, opts)
+}
+
+// ListInstancesCalls gets all the calls that were made to ListInstances.
+// Check the length with:
+//
+//	len(mockedInstanceLister.ListInstancesCalls())
+func (mock *InstanceListerMock) ListInstancesCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListInstances.RLock()
+	calls = mock.calls.ListInstances
+	mock.lockListInstances.RUnlock()
+	return calls
+}
+
+// InstanceStatsGetterMock is a mock implementation of collector.InstanceStatsGetter.
+//
+//	func TestSomethingThatUsesInstanceStatsGetter(t *testing.T) {
+//
+//		// make and configure a mocked collector.InstanceStatsGetter
+//		mockedInstanceStatsGetter := &InstanceStatsGetterMock{
+//			GetInstanceStatsFunc: func(ctx context.Context, linodeID int) (*linodego.InstanceStats, error) {
+//				panic("mock out the GetInstanceStats method")
+//			},
+//		}
+//
+//		// use mockedInstanceStatsGetter in code that requires collector.InstanceStatsGetter
+//		// and then make assertions.
+//
+//	}
+type InstanceStatsGetterMock struct {
+	// GetInstanceStatsFunc mocks the GetInstanceStats method.
+	GetInstanceStatsFunc func(ctx context.Context, linodeID int) (*linodego.InstanceStats, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetInstanceStats holds details about calls to the GetInstanceStats method.
+		GetInstanceStats []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// LinodeID is the linodeID argument value.
+			LinodeID int
+		}
+	}
+	lockGetInstanceStats sync.RWMutex
+}
+
+// GetInstanceStats calls GetInstanceStatsFunc.
+func (mock *InstanceStatsGetterMock) GetInstanceStats(ctx context.Context, linodeID int) (*linodego.InstanceStats, error) {
+	if mock.GetInstanceStatsFunc == nil {
+		panic("InstanceStatsGetterMock.GetInstanceStatsFunc: method is nil but InstanceStatsGetter.GetInstanceStats was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		LinodeID int
+	}{
+		Ctx:      ctx,
+		LinodeID: linodeID,
+	}
+	mock.lockGetInstanceStats.Lock()
+	mock.calls.GetInstanceStats = append(mock.calls.GetInstanceStats, callInfo)
+	mock.lockGetInstanceStats.Unlock()
+	return mock.GetInstanceStatsFunc(ctx, linodeID)
+}
+
+// GetInstanceStatsCalls gets all the calls that were made to GetInstanceStats.
+// Check the length with:
+//
+//	len(mockedInstanceStatsGetter.GetInstanceStatsCalls())
+func (mock *InstanceStatsGetterMock) GetInstanceStatsCalls() []struct {
+	Ctx      context.Context
+	LinodeID int
+} {
+	var calls []struct {
+		Ctx      context.Context
+		LinodeID int
+	}
+	mock.lockGetInstanceStats.RLock()
+	calls = mock.calls.GetInstanceStats
+	mock.lockGetInstanceStats.RUnlock()
+	return calls
+}
+
+// VolumeListerMock is a mock implementation of collector.VolumeLister.
+//
+//	func TestSomethingThatUsesVolumeLister(t *testing.T) {
+//
+//		// make and configure a mocked collector.VolumeLister
+//		mockedVolumeLister := &VolumeListerMock{
+//			ListVolumesFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Volume, error) {
+//				panic("mock out the ListVolumes method")
+//			},
+//		}
+//
+//		// use mockedVolumeLister in code that requires collector.VolumeLister
+//		// and then make assertions.
+//
+//	}
+type VolumeListerMock struct {
+	// ListVolumesFunc mocks the ListVolumes method.
+	ListVolumesFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Volume, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListVolumes holds details about calls to the ListVolumes method.
+		ListVolumes []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+	}
+	lockListVolumes sync.RWMutex
+}
+
+// ListVolumes calls ListVolumesFunc.
+func (mock *VolumeListerMock) ListVolumes(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Volume, error) {
+	if mock.ListVolumesFunc == nil {
+		panic("VolumeListerMock.ListVolumesFunc: method is nil but VolumeLister.ListVolumes was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListVolumes.Lock()
+	mock.calls.ListVolumes = append(mock.calls.ListVolumes, callInfo)
+	mock.lockListVolumes.Unlock()
+	return mock.ListVolumesFunc(ctx, opts)
+}
+
+// ListVolumesCalls gets all the calls that were made to ListVolumes.
+// Check the length with:
+//
+//	len(mockedVolumeLister.ListVolumesCalls())
+func (mock *VolumeListerMock) ListVolumesCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListVolumes.RLock()
+	calls = mock.calls.ListVolumes
+	mock.lockListVolumes.RUnlock()
+	return calls
+}
+
+// NodeBalancerListerMock is a mock implementation of collector.NodeBalancerLister.
+//
+//	func TestSomethingThatUsesNodeBalancerLister(t *testing.T) {
+//
+//		// make and configure a mocked collector.NodeBalancerLister
+//		mockedNodeBalancerLister := &NodeBalancerListerMock{
+//			ListNodeBalancersFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.NodeBalancer, error) {
+//				panic("mock out the ListNodeBalancers method")
+//			},
+//		}
+//
+//		// use mockedNodeBalancerLister in code that requires collector.NodeBalancerLister
+//		// and then make assertions.
+//
+//	}
+type NodeBalancerListerMock struct {
+	// ListNodeBalancersFunc mocks the ListNodeBalancers method.
+	ListNodeBalancersFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.NodeBalancer, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListNodeBalancers holds details about calls to the ListNodeBalancers method.
+		ListNodeBalancers []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+	}
+	lockListNodeBalancers sync.RWMutex
+}
+
+// ListNodeBalancers calls ListNodeBalancersFunc.
+func (mock *NodeBalancerListerMock) ListNodeBalancers(ctx context.Context, opts *linodego.ListOptions) ([]linodego.NodeBalancer, error) {
+	if mock.ListNodeBalancersFunc == nil {
+		panic("NodeBalancerListerMock.ListNodeBalancersFunc: method is nil but NodeBalancerLister.ListNodeBalancers was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListNodeBalancers.Lock()
+	mock.calls.ListNodeBalancers = append(mock.calls.ListNodeBalancers, callInfo)
+	mock.lockListNodeBalancers.Unlock()
+	return mock.ListNodeBalancersFunc(ctx, opts)
+}
+
+// ListNodeBalancersCalls gets all the calls that were made to ListNodeBalancers.
+// Check the length with:
+//
+//	len(mockedNodeBalancerLister.ListNodeBalancersCalls())
+func (mock *NodeBalancerListerMock) ListNodeBalancersCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListNodeBalancers.RLock()
+	calls = mock.calls.ListNodeBalancers
+	mock.lockListNodeBalancers.RUnlock()
+	return calls
+}
+
+// NodeBalancerStatsGetterMock is a mock implementation of collector.NodeBalancerStatsGetter.
+//
+//	func TestSomethingThatUsesNodeBalancerStatsGetter(t *testing.T) {
+//
+//		// make and configure a mocked collector.NodeBalancerStatsGetter
+//		mockedNodeBalancerStatsGetter := &NodeBalancerStatsGetterMock{
+//			GetNodeBalancerStatsFunc: func(ctx context.Context, nodebalancerID int) (*linodego.NodeBalancerStats, error) {
+//				panic("mock out the GetNodeBalancerStats method")
+//			},
+//		}
+//
+//		// use mockedNodeBalancerStatsGetter in code that requires collector.NodeBalancerStatsGetter
+//		// and then make assertions.
+//
+//	}
+type NodeBalancerStatsGetterMock struct {
+	// GetNodeBalancerStatsFunc mocks the GetNodeBalancerStats method.
+	GetNodeBalancerStatsFunc func(ctx context.Context, nodebalancerID int) (*linodego.NodeBalancerStats, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetNodeBalancerStats holds details about calls to the GetNodeBalancerStats method.
+		GetNodeBalancerStats []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// NodebalancerID is the nodebalancerID argument value.
+			NodebalancerID int
+		}
+	}
+	lockGetNodeBalancerStats sync.RWMutex
+}
+
+// GetNodeBalancerStats calls GetNodeBalancerStatsFunc.
+func (mock *NodeBalancerStatsGetterMock) GetNodeBalancerStats(ctx context.Context, nodebalancerID int) (*linodego.NodeBalancerStats, error) {
+	if mock.GetNodeBalancerStatsFunc == nil {
+		panic("NodeBalancerStatsGetterMock.GetNodeBalancerStatsFunc: method is nil but NodeBalancerStatsGetter.GetNodeBalancerStats was just called")
+	}
+	callInfo := struct {
+		Ctx            context.Context
+		NodebalancerID int
+	}{
+		Ctx:            ctx,
+		NodebalancerID: nodebalancerID,
+	}
+	mock.lockGetNodeBalancerStats.Lock()
+	mock.calls.GetNodeBalancerStats = append(mock.calls.GetNodeBalancerStats, callInfo)
+	mock.lockGetNodeBalancerStats.Unlock()
+	return mock.GetNodeBalancerStatsFunc(ctx, nodebalancerID)
+}
+
+// GetNodeBalancerStatsCalls gets all the calls that were made to GetNodeBalancerStats.
+// Check the length with:
+//
+//	len(mockedNodeBalancerStatsGetter.GetNodeBalancerStatsCalls())
+func (mock *NodeBalancerStatsGetterMock) GetNodeBalancerStatsCalls() []struct {
+	Ctx            context.Context
+	NodebalancerID int
+} {
+	var calls []struct {
+		Ctx            context.Context
+		NodebalancerID int
+	}
+	mock.lockGetNodeBalancerStats.RLock()
+	calls = mock.calls.GetNodeBalancerStats
+	mock.lockGetNodeBalancerStats.RUnlock()
+	return calls
+}
+
+// LKEClusterListerMock is a mock implementation of collector.LKEClusterLister.
+//
+//	func TestSomethingThatUsesLKEClusterLister(t *testing.T) {
+//
+//		// make and configure a mocked collector.LKEClusterLister
+//		mockedLKEClusterLister := &LKEClusterListerMock{
+//			ListLKEClustersFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.LKECluster, error) {
+//				panic("mock out the ListLKEClusters method")
+//			},
+//		}
+//
+//		// use mockedLKEClusterLister in code that requires collector.LKEClusterLister
+//		// and then make assertions.
+//
+//	}
+type LKEClusterListerMock struct {
+	// ListLKEClustersFunc mocks the ListLKEClusters method.
+	ListLKEClustersFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.LKECluster, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListLKEClusters holds details about calls to the ListLKEClusters method.
+		ListLKEClusters []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+	}
+	lockListLKEClusters sync.RWMutex
+}
+
+// ListLKEClusters calls ListLKEClustersFunc.
+func (mock *LKEClusterListerMock) ListLKEClusters(ctx context.Context, opts *linodego.ListOptions) ([]linodego.LKECluster, error) {
+	if mock.ListLKEClustersFunc == nil {
+		panic("LKEClusterListerMock.ListLKEClustersFunc: method is nil but LKEClusterLister.ListLKEClusters was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListLKEClusters.Lock()
+	mock.calls.ListLKEClusters = append(mock.calls.ListLKEClusters, callInfo)
+	mock.lockListLKEClusters.Unlock()
+	return mock.ListLKEClustersFunc(ctx, opts)
+}
+
+// ListLKEClustersCalls gets all the calls that were made to ListLKEClusters.
+// Check the length with:
+//
+//	len(mockedLKEClusterLister.ListLKEClustersCalls())
+func (mock *LKEClusterListerMock) ListLKEClustersCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListLKEClusters.RLock()
+	calls = mock.calls.ListLKEClusters
+	mock.lockListLKEClusters.RUnlock()
+	return calls
+}
+
+// LKENodePoolListerMock is a mock implementation of collector.LKENodePoolLister.
+//
+//	func TestSomethingThatUsesLKENodePoolLister(t *testing.T) {
+//
+//		// make and configure a mocked collector.LKENodePoolLister
+//		mockedLKENodePoolLister := &LKENodePoolListerMock{
+//			ListLKENodePoolsFunc: func(ctx context.Context, clusterID int, opts *linodego.ListOptions) ([]linodego.LKENodePool, error) {
+//				panic("mock out the ListLKENodePools method")
+//			},
+//		}
+//
+//		// use mockedLKENodePoolLister in code that requires collector.LKENodePoolLister
+//		// and then make assertions.
+//
+//	}
+type LKENodePoolListerMock struct {
+	// ListLKENodePoolsFunc mocks the ListLKENodePools method.
+	ListLKENodePoolsFunc func(ctx context.Context, clusterID int, opts *linodego.ListOptions) ([]linodego.LKENodePool, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListLKENodePools holds details about calls to the ListLKENodePools method.
+		ListLKENodePools []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ClusterID is the clusterID argument value.
+			ClusterID int
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+	}
+	lockListLKENodePools sync.RWMutex
+}
+
+// ListLKENodePools calls ListLKENodePoolsFunc.
+func (mock *LKENodePoolListerMock) ListLKENodePools(ctx context.Context, clusterID int, opts *linodego.ListOptions) ([]linodego.LKENodePool, error) {
+	if mock.ListLKENodePoolsFunc == nil {
+		panic("LKENodePoolListerMock.ListLKENodePoolsFunc: method is nil but LKENodePoolLister.ListLKENodePools was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ClusterID int
+		Opts      *linodego.ListOptions
+	}{
+		Ctx:       ctx,
+		ClusterID: clusterID,
+		Opts:      opts,
+	}
+	mock.lockListLKENodePools.Lock()
+	mock.calls.ListLKENodePools = append(mock.calls.ListLKENodePools, callInfo)
+	mock.lockListLKENodePools.Unlock()
+	return mock.ListLKENodePoolsFunc(ctx, clusterID, opts)
+}
+
+// ListLKENodePoolsCalls gets all the calls that were made to ListLKENodePools.
+// Check the length with:
+//
+//	len(mockedLKENodePoolLister.ListLKENodePoolsCalls())
+func (mock *LKENodePoolListerMock) ListLKENodePoolsCalls() []struct {
+	Ctx       context.Context
+	ClusterID int
+	Opts      *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ClusterID int
+		Opts      *linodego.ListOptions
+	}
+	mock.lockListLKENodePools.RLock()
+	calls = mock.calls.ListLKENodePools
+	mock.lockListLKENodePools.RUnlock()
+	return calls
+}
+
+// ObjectStorageBucketListerMock is a mock implementation of collector.ObjectStorageBucketLister.
+//
+//	func TestSomethingThatUsesObjectStorageBucketLister(t *testing.T) {
+//
+//		// make and configure a mocked collector.ObjectStorageBucketLister
+//		mockedObjectStorageBucketLister := &ObjectStorageBucketListerMock{
+//			ListObjectStorageBucketsFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageBucket, error) {
+//				panic("mock out the ListObjectStorageBuckets method")
+//			},
+//		}
+//
+//		// use mockedObjectStorageBucketLister in code that requires collector.ObjectStorageBucketLister
+//		// and then make assertions.
+//
+//	}
+type ObjectStorageBucketListerMock struct {
+	// ListObjectStorageBucketsFunc mocks the ListObjectStorageBuckets method.
+	ListObjectStorageBucketsFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageBucket, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListObjectStorageBuckets holds details about calls to the ListObjectStorageBuckets method.
+		ListObjectStorageBuckets []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+	}
+	lockListObjectStorageBuckets sync.RWMutex
+}
+
+// ListObjectStorageBuckets calls ListObjectStorageBucketsFunc.
+func (mock *ObjectStorageBucketListerMock) ListObjectStorageBuckets(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageBucket, error) {
+	if mock.ListObjectStorageBucketsFunc == nil {
+		panic("ObjectStorageBucketListerMock.ListObjectStorageBucketsFunc: method is nil but ObjectStorageBucketLister.ListObjectStorageBuckets was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListObjectStorageBuckets.Lock()
+	mock.calls.ListObjectStorageBuckets = append(mock.calls.ListObjectStorageBuckets, callInfo)
+	mock.lockListObjectStorageBuckets.Unlock()
+	return mock.ListObjectStorageBucketsFunc(ctx, opts)
+}
+
+// ListObjectStorageBucketsCalls gets all the calls that were made to ListObjectStorageBuckets.
+// Check the length with:
+//
+//	len(mockedObjectStorageBucketLister.ListObjectStorageBucketsCalls())
+func (mock *ObjectStorageBucketListerMock) ListObjectStorageBucketsCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListObjectStorageBuckets.RLock()
+	calls = mock.calls.ListObjectStorageBuckets
+	mock.lockListObjectStorageBuckets.RUnlock()
+	return calls
+}
+
+// ObjectStorageClientMock is a mock implementation of collector.ObjectStorageClient.
+//
+//	func TestSomethingThatUsesObjectStorageClient(t *testing.T) {
+//
+//		// make and configure a mocked collector.ObjectStorageClient
+//		mockedObjectStorageClient := &ObjectStorageClientMock{
+//			GetObjectStorageBucketAccessV2Func: func(ctx context.Context, clusterOrRegionID string, label string) (*linodego.ObjectStorageBucketAccessV2, error) {
+//				panic("mock out the GetObjectStorageBucketAccessV2 method")
+//			},
+//			GetObjectStorageQuotaUsageFunc: func(ctx context.Context, quotaID string) (*linodego.ObjectStorageQuotaUsage, error) {
+//				panic("mock out the GetObjectStorageQuotaUsage method")
+//			},
+//			GetObjectStorageTransferFunc: func(ctx context.Context) (*linodego.ObjectStorageTransfer, error) {
+//				panic("mock out the GetObjectStorageTransfer method")
+//			},
+//			ListObjectStorageEndpointsFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageEndpoint, error) {
+//				panic("mock out the ListObjectStorageEndpoints method")
+//			},
+//			ListObjectStorageKeysFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageKey, error) {
+//				panic("mock out the ListObjectStorageKeys method")
+//			},
+//			ListObjectStorageQuotasFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageQuota, error) {
+//				panic("mock out the ListObjectStorageQuotas method")
+//			},
+//		}
+//
+//		// use mockedObjectStorageClient in code that requires collector.ObjectStorageClient
+//		// and then make assertions.
+//
+//	}
+type ObjectStorageClientMock struct {
+	// GetObjectStorageBucketAccessV2Func mocks the GetObjectStorageBucketAccessV2 method.
+	GetObjectStorageBucketAccessV2Func func(ctx context.Context, clusterOrRegionID string, label string) (*linodego.ObjectStorageBucketAccessV2, error)
+
+	// GetObjectStorageQuotaUsageFunc mocks the GetObjectStorageQuotaUsage method.
+	GetObjectStorageQuotaUsageFunc func(ctx context.Context, quotaID string) (*linodego.ObjectStorageQuotaUsage, error)
+
+	// GetObjectStorageTransferFunc mocks the GetObjectStorageTransfer method.
+	GetObjectStorageTransferFunc func(ctx context.Context) (*linodego.ObjectStorageTransfer, error)
+
+	// ListObjectStorageEndpointsFunc mocks the ListObjectStorageEndpoints method.
+	ListObjectStorageEndpointsFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageEndpoint, error)
+
+	// ListObjectStorageKeysFunc mocks the ListObjectStorageKeys method.
+	ListObjectStorageKeysFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageKey, error)
+
+	// ListObjectStorageQuotasFunc mocks the ListObjectStorageQuotas method.
+	ListObjectStorageQuotasFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageQuota, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetObjectStorageBucketAccessV2 holds details about calls to the GetObjectStorageBucketAccessV2 method.
+		GetObjectStorageBucketAccessV2 []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ClusterOrRegionID is the clusterOrRegionID argument value.
+			ClusterOrRegionID string
+			// Label is the label argument value.
+			Label string
+		}
+		// GetObjectStorageQuotaUsage holds details about calls to the GetObjectStorageQuotaUsage method.
+		GetObjectStorageQuotaUsage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// QuotaID is the quotaID argument value.
+			QuotaID string
+		}
+		// GetObjectStorageTransfer holds details about calls to the GetObjectStorageTransfer method.
+		GetObjectStorageTransfer []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// ListObjectStorageEndpoints holds details about calls to the ListObjectStorageEndpoints method.
+		ListObjectStorageEndpoints []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+		// ListObjectStorageKeys holds details about calls to the ListObjectStorageKeys method.
+		ListObjectStorageKeys []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+		// ListObjectStorageQuotas holds details about calls to the ListObjectStorageQuotas method.
+		ListObjectStorageQuotas []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+	}
+	lockGetObjectStorageBucketAccessV2 sync.RWMutex
+	lockGetObjectStorageQuotaUsage     sync.RWMutex
+	lockGetObjectStorageTransfer       sync.RWMutex
+	lockListObjectStorageEndpoints     sync.RWMutex
+	lockListObjectStorageKeys          sync.RWMutex
+	lockListObjectStorageQuotas        sync.RWMutex
+}
+
+// GetObjectStorageBucketAccessV2 calls GetObjectStorageBucketAccessV2Func.
+func (mock *ObjectStorageClientMock) GetObjectStorageBucketAccessV2(ctx context.Context, clusterOrRegionID string, label string) (*linodego.ObjectStorageBucketAccessV2, error) {
+	if mock.GetObjectStorageBucketAccessV2Func == nil {
+		panic("ObjectStorageClientMock.GetObjectStorageBucketAccessV2Func: method is nil but ObjectStorageClient.GetObjectStorageBucketAccessV2 was just called")
+	}
+	callInfo := struct {
+		Ctx               context.Context
+		ClusterOrRegionID string
+		Label             string
+	}{
+		Ctx:               ctx,
+		ClusterOrRegionID: clusterOrRegionID,
+		Label:             label,
+	}
+	mock.lockGetObjectStorageBucketAccessV2.Lock()
+	mock.calls.GetObjectStorageBucketAccessV2 = append(mock.calls.GetObjectStorageBucketAccessV2, callInfo)
+	mock.lockGetObjectStorageBucketAccessV2.Unlock()
+	return mock.GetObjectStorageBucketAccessV2Func(ctx, clusterOrRegionID, label)
+}
+
+// GetObjectStorageBucketAccessV2Calls gets all the calls that were made to GetObjectStorageBucketAccessV2.
+// Check the length with:
+//
+//	len(mockedObjectStorageClient.GetObjectStorageBucketAccessV2Calls())
+func (mock *ObjectStorageClientMock) GetObjectStorageBucketAccessV2Calls() []struct {
+	Ctx               context.Context
+	ClusterOrRegionID string
+	Label             string
+} {
+	var calls []struct {
+		Ctx               context.Context
+		ClusterOrRegionID string
+		Label             string
+	}
+	mock.lockGetObjectStorageBucketAccessV2.RLock()
+	calls = mock.calls.GetObjectStorageBucketAccessV2
+	mock.lockGetObjectStorageBucketAccessV2.RUnlock()
+	return calls
+}
+
+// GetObjectStorageQuotaUsage calls GetObjectStorageQuotaUsageFunc.
+func (mock *ObjectStorageClientMock) GetObjectStorageQuotaUsage(ctx context.Context, quotaID string) (*linodego.ObjectStorageQuotaUsage, error) {
+	if mock.GetObjectStorageQuotaUsageFunc == nil {
+		panic("ObjectStorageClientMock.GetObjectStorageQuotaUsageFunc: method is nil but ObjectStorageClient.GetObjectStorageQuotaUsage was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		QuotaID string
+	}{
+		Ctx:     ctx,
+		QuotaID: quotaID,
+	}
+	mock.lockGetObjectStorageQuotaUsage.Lock()
+	mock.calls.GetObjectStorageQuotaUsage = append(mock.calls.GetObjectStorageQuotaUsage, callInfo)
+	mock.lockGetObjectStorageQuotaUsage.Unlock()
+	return mock.GetObjectStorageQuotaUsageFunc(ctx, quotaID)
+}
+
+// GetObjectStorageQuotaUsageCalls gets all the calls that were made to GetObjectStorageQuotaUsage.
+// Check the length with:
+//
+//	len(mockedObjectStorageClient.GetObjectStorageQuotaUsageCalls())
+func (mock *ObjectStorageClientMock) GetObjectStorageQuotaUsageCalls() []struct {
+	Ctx     context.Context
+	QuotaID string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		QuotaID string
+	}
+	mock.lockGetObjectStorageQuotaUsage.RLock()
+	calls = mock.calls.GetObjectStorageQuotaUsage
+	mock.lockGetObjectStorageQuotaUsage.RUnlock()
+	return calls
+}
+
+// GetObjectStorageTransfer calls GetObjectStorageTransferFunc.
+func (mock *ObjectStorageClientMock) GetObjectStorageTransfer(ctx context.Context) (*linodego.ObjectStorageTransfer, error) {
+	if mock.GetObjectStorageTransferFunc == nil {
+		panic("ObjectStorageClientMock.GetObjectStorageTransferFunc: method is nil but ObjectStorageClient.GetObjectStorageTransfer was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetObjectStorageTransfer.Lock()
+	mock.calls.GetObjectStorageTransfer = append(mock.calls.GetObjectStorageTransfer, callInfo)
+	mock.lockGetObjectStorageTransfer.Unlock()
+	return mock.GetObjectStorageTransferFunc(ctx)
+}
+
+// GetObjectStorageTransferCalls gets all the calls that were made to GetObjectStorageTransfer.
+// Check the length with:
+//
+//	len(mockedObjectStorageClient.GetObjectStorageTransferCalls())
+func (mock *ObjectStorageClientMock) GetObjectStorageTransferCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetObjectStorageTransfer.RLock()
+	calls = mock.calls.GetObjectStorageTransfer
+	mock.lockGetObjectStorageTransfer.RUnlock()
+	return calls
+}
+
+// ListObjectStorageEndpoints calls ListObjectStorageEndpointsFunc.
+func (mock *ObjectStorageClientMock) ListObjectStorageEndpoints(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageEndpoint, error) {
+	if mock.ListObjectStorageEndpointsFunc == nil {
+		panic("ObjectStorageClientMock.ListObjectStorageEndpointsFunc: method is nil but ObjectStorageClient.ListObjectStorageEndpoints was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListObjectStorageEndpoints.Lock()
+	mock.calls.ListObjectStorageEndpoints = append(mock.calls.ListObjectStorageEndpoints, callInfo)
+	mock.lockListObjectStorageEndpoints.Unlock()
+	return mock.ListObjectStorageEndpointsFunc(ctx, opts)
+}
+
+// ListObjectStorageEndpointsCalls gets all the calls that were made to ListObjectStorageEndpoints.
+// Check the length with:
+//
+//	len(mockedObjectStorageClient.ListObjectStorageEndpointsCalls())
+func (mock *ObjectStorageClientMock) ListObjectStorageEndpointsCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListObjectStorageEndpoints.RLock()
+	calls = mock.calls.ListObjectStorageEndpoints
+	mock.lockListObjectStorageEndpoints.RUnlock()
+	return calls
+}
+
+// ListObjectStorageKeys calls ListObjectStorageKeysFunc.
+func (mock *ObjectStorageClientMock) ListObjectStorageKeys(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageKey, error) {
+	if mock.ListObjectStorageKeysFunc == nil {
+		panic("ObjectStorageClientMock.ListObjectStorageKeysFunc: method is nil but ObjectStorageClient.ListObjectStorageKeys was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListObjectStorageKeys.Lock()
+	mock.calls.ListObjectStorageKeys = append(mock.calls.ListObjectStorageKeys, callInfo)
+	mock.lockListObjectStorageKeys.Unlock()
+	return mock.ListObjectStorageKeysFunc(ctx, opts)
+}
+
+// ListObjectStorageKeysCalls gets all the calls that were made to ListObjectStorageKeys.
+// Check the length with:
+//
+//	len(mockedObjectStorageClient.ListObjectStorageKeysCalls())
+func (mock *ObjectStorageClientMock) ListObjectStorageKeysCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListObjectStorageKeys.RLock()
+	calls = mock.calls.ListObjectStorageKeys
+	mock.lockListObjectStorageKeys.RUnlock()
+	return calls
+}
+
+// ListObjectStorageQuotas calls ListObjectStorageQuotasFunc.
+func (mock *ObjectStorageClientMock) ListObjectStorageQuotas(ctx context.Context, opts *linodego.ListOptions) ([]linodego.ObjectStorageQuota, error) {
+	if mock.ListObjectStorageQuotasFunc == nil {
+		panic("ObjectStorageClientMock.ListObjectStorageQuotasFunc: method is nil but ObjectStorageClient.ListObjectStorageQuotas was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListObjectStorageQuotas.Lock()
+	mock.calls.ListObjectStorageQuotas = append(mock.calls.ListObjectStorageQuotas, callInfo)
+	mock.lockListObjectStorageQuotas.Unlock()
+	return mock.ListObjectStorageQuotasFunc(ctx, opts)
+}
+
+// ListObjectStorageQuotasCalls gets all the calls that were made to ListObjectStorageQuotas.
+// Check the length with:
+//
+//	len(mockedObjectStorageClient.ListObjectStorageQuotasCalls())
+func (mock *ObjectStorageClientMock) ListObjectStorageQuotasCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListObjectStorageQuotas.RLock()
+	calls = mock.calls.ListObjectStorageQuotas
+	mock.lockListObjectStorageQuotas.RUnlock()
+	return calls
+}
+
+// DomainListerMock is a mock implementation of collector.DomainLister.
+//
+//	func TestSomethingThatUsesDomainLister(t *testing.T) {
+//
+//		// make and configure a mocked collector.DomainLister
+//		mockedDomainLister := &DomainListerMock{
+//			ListDomainsFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Domain, error) {
+//				panic("mock out the ListDomains method")
+//			},
+//		}
+//
+//		// use mockedDomainLister in code that requires collector.DomainLister
+//		// and then make assertions.
+//
+//	}
+type DomainListerMock struct {
+	// ListDomainsFunc mocks the ListDomains method.
+	ListDomainsFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Domain, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListDomains holds details about calls to the ListDomains method.
+		ListDomains []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+	}
+	lockListDomains sync.RWMutex
+}
+
+// ListDomains calls ListDomainsFunc.
+func (mock *DomainListerMock) ListDomains(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Domain, error) {
+	if mock.ListDomainsFunc == nil {
+		panic("DomainListerMock.ListDomainsFunc: method is nil but DomainLister.ListDomains was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListDomains.Lock()
+	mock.calls.ListDomains = append(mock.calls.ListDomains, callInfo)
+	mock.lockListDomains.Unlock()
+	return mock.ListDomainsFunc(ctx, opts)
+}
+
+// ListDomainsCalls gets all the calls that were made to ListDomains.
+// Check the length with:
+//
+//	len(mockedDomainLister.ListDomainsCalls())
+func (mock *DomainListerMock) ListDomainsCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListDomains.RLock()
+	calls = mock.calls.ListDomains
+	mock.lockListDomains.RUnlock()
+	return calls
+}
+
+// TagListerMock is a mock implementation of collector.TagLister.
+//
+//	func TestSomethingThatUsesTagLister(t *testing.T) {
+//
+//		// make and configure a mocked collector.TagLister
+//		mockedTagLister := &TagListerMock{
+//			ListTaggedObjectsFunc: func(ctx context.Context, label string, opts *linodego.ListOptions) (linodego.TaggedObjectList, error) {
+//				panic("mock out the ListTaggedObjects method")
+//			},
+//			ListTagsFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Tag, error) {
+//				panic("mock out the ListTags method")
+//			},
+//		}
+//
+//		// use mockedTagLister in code that requires collector.TagLister
+//		// and then make assertions.
+//
+//	}
+type TagListerMock struct {
+	// ListTaggedObjectsFunc mocks the ListTaggedObjects method.
+	ListTaggedObjectsFunc func(ctx context.Context, label string, opts *linodego.ListOptions) (linodego.TaggedObjectList, error)
+
+	// ListTagsFunc mocks the ListTags method.
+	ListTagsFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Tag, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListTaggedObjects holds details about calls to the ListTaggedObjects method.
+		ListTaggedObjects []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Label is the label argument value.
+			Label string
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+		// ListTags holds details about calls to the ListTags method.
+		ListTags []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+	}
+	lockListTaggedObjects sync.RWMutex
+	lockListTags          sync.RWMutex
+}
+
+// ListTaggedObjects calls ListTaggedObjectsFunc.
+func (mock *TagListerMock) ListTaggedObjects(ctx context.Context, label string, opts *linodego.ListOptions) (linodego.TaggedObjectList, error) {
+	if mock.ListTaggedObjectsFunc == nil {
+		panic("TagListerMock.ListTaggedObjectsFunc: method is nil but TagLister.ListTaggedObjects was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Label string
+		Opts  *linodego.ListOptions
+	}{
+		Ctx:   ctx,
+		Label: label,
+		Opts:  opts,
+	}
+	mock.lockListTaggedObjects.Lock()
+	mock.calls.ListTaggedObjects = append(mock.calls.ListTaggedObjects, callInfo)
+	mock.lockListTaggedObjects.Unlock()
+	return mock.ListTaggedObjectsFunc(ctx, label, opts)
+}
+
+// ListTaggedObjectsCalls gets all the calls that were made to ListTaggedObjects.
+// Check the length with:
+//
+//	len(mockedTagLister.ListTaggedObjectsCalls())
+func (mock *TagListerMock) ListTaggedObjectsCalls() []struct {
+	Ctx   context.Context
+	Label string
+	Opts  *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Label string
+		Opts  *linodego.ListOptions
+	}
+	mock.lockListTaggedObjects.RLock()
+	calls = mock.calls.ListTaggedObjects
+	mock.lockListTaggedObjects.RUnlock()
+	return calls
+}
+
+// ListTags calls ListTagsFunc.
+func (mock *TagListerMock) ListTags(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Tag, error) {
+	if mock.ListTagsFunc == nil {
+		panic("TagListerMock.ListTagsFunc: method is nil but TagLister.ListTags was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListTags.Lock()
+	mock.calls.ListTags = append(mock.calls.ListTags, callInfo)
+	mock.lockListTags.Unlock()
+	return mock.ListTagsFunc(ctx, opts)
+}
+
+// ListTagsCalls gets all the calls that were made to ListTags.
+// Check the length with:
+//
+//	len(mockedTagLister.ListTagsCalls())
+func (mock *TagListerMock) ListTagsCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListTags.RLock()
+	calls = mock.calls.ListTags
+	mock.lockListTags.RUnlock()
+	return calls
+}
+
+// TicketListerMock is a mock implementation of collector.TicketLister.
+//
+//	func TestSomethingThatUsesTicketLister(t *testing.T) {
+//
+//		// make and configure a mocked collector.TicketLister
+//		mockedTicketLister := &TicketListerMock{
+//			ListTicketsFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Ticket, error) {
+//				panic("mock out the ListTickets method")
+//			},
+//		}
+//
+//		// use mockedTicketLister in code that requires collector.TicketLister
+//		// and then make assertions.
+//
+//	}
+type TicketListerMock struct {
+	// ListTicketsFunc mocks the ListTickets method.
+	ListTicketsFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Ticket, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListTickets holds details about calls to the ListTickets method.
+		ListTickets []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+	}
+	lockListTickets sync.RWMutex
+}
+
+// ListTickets calls ListTicketsFunc.
+func (mock *TicketListerMock) ListTickets(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Ticket, error) {
+	if mock.ListTicketsFunc == nil {
+		panic("TicketListerMock.ListTicketsFunc: method is nil but TicketLister.ListTickets was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListTickets.Lock()
+	mock.calls.ListTickets = append(mock.calls.ListTickets, callInfo)
+	mock.lockListTickets.Unlock()
+	return mock.ListTicketsFunc(ctx, opts)
+}
+
+// ListTicketsCalls gets all the calls that were made to ListTickets.
+// Check the length with:
+//
+//	len(mockedTicketLister.ListTicketsCalls())
+func (mock *TicketListerMock) ListTicketsCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListTickets.RLock()
+	calls = mock.calls.ListTickets
+	mock.lockListTickets.RUnlock()
+	return calls
+}
+
+// UserListerMock is a mock implementation of collector.UserLister.
+//
+//	func TestSomethingThatUsesUserLister(t *testing.T) {
+//
+//		// make and configure a mocked collector.UserLister
+//		mockedUserLister := &UserListerMock{
+//			ListUsersFunc: func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.User, error) {
+//				panic("mock out the ListUsers method")
+//			},
+//		}
+//
+//		// use mockedUserLister in code that requires collector.UserLister
+//		// and then make assertions.
+//
+//	}
+type UserListerMock struct {
+	// ListUsersFunc mocks the ListUsers method.
+	ListUsersFunc func(ctx context.Context, opts *linodego.ListOptions) ([]linodego.User, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ListUsers holds details about calls to the ListUsers method.
+		ListUsers []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *linodego.ListOptions
+		}
+	}
+	lockListUsers sync.RWMutex
+}
+
+// ListUsers calls ListUsersFunc.
+func (mock *UserListerMock) ListUsers(ctx context.Context, opts *linodego.ListOptions) ([]linodego.User, error) {
+	if mock.ListUsersFunc == nil {
+		panic("UserListerMock.ListUsersFunc: method is nil but UserLister.ListUsers was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockListUsers.Lock()
+	mock.calls.ListUsers = append(mock.calls.ListUsers, callInfo)
+	mock.lockListUsers.Unlock()
+	return mock.ListUsersFunc(ctx, opts)
+}
+
+// ListUsersCalls gets all the calls that were made to ListUsers.
+// Check the length with:
+//
+//	len(mockedUserLister.ListUsersCalls())
+func (mock *UserListerMock) ListUsersCalls() []struct {
+	Ctx  context.Context
+	Opts *linodego.ListOptions
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *linodego.ListOptions
+	}
+	mock.lockListUsers.RLock()
+	calls = mock.calls.ListUsers
+	mock.lockListUsers.RUnlock()
+	return calls
+}
@@ -0,0 +1,115 @@
+// Package push periodically gathers Prometheus metrics and pushes them to a receiver
+package push
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	prometheusbridge "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+const (
+	// ProtocolGRPC is OTLP/gRPC
+	ProtocolGRPC = "grpc"
+	// ProtocolHTTP is OTLP/HTTP (protobuf)
+	ProtocolHTTP = "http"
+)
+
+// OTLPOptions configures an OTLP exporter
+type OTLPOptions struct {
+	// Endpoint is the receiver's host:port or URL (e.g. http://localhost:4318/v1/metrics)
+	Endpoint string
+	// Protocol is either ProtocolGRPC or ProtocolHTTP
+	Protocol string
+	// Insecure disables TLS (for endpoints without a scheme)
+	Insecure bool
+	// Headers are sent with each export (e.g. for authentication)
+	Headers map[string]string
+	// Interval is the duration between exports
+	Interval time.Duration
+	// Attributes are the resource attributes (in addition to service.name) of the exported metrics
+	Attributes map[string]string
+}
+
+// OTLP periodically gathers metrics and exports them to an OpenTelemetry (OTLP) receiver
+type OTLP struct {
+	provider *metric.MeterProvider
+}
+
+// NewOTLP creates an OTLP that exports the metrics gathered by gatherer every opts.Interval until it is shut down
+func NewOTLP(ctx context.Context, gatherer prometheus.Gatherer, opts OTLPOptions) (*OTLP, error) {
+	log.Println("[NewOTLP] Entered")
+	exporter, err := newOTLPExporter(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := []attribute.KeyValue{
+		attribute.String("service.name", "linode-exporter"),
+	}
+	for k, v := range opts.Attributes {
+		attributes = append(attributes, attribute.String(k, v))
+	}
+
+	reader := metric.NewPeriodicReader(exporter,
+		metric.WithInterval(opts.Interval),
+		metric.WithProducer(prometheusbridge.NewMetricProducer(prometheusbridge.WithGatherer(gatherer))),
+	)
+	return &OTLP{
+		provider: metric.NewMeterProvider(
+			metric.WithReader(reader),
+			metric.WithResource(resource.NewSchemaless(attributes...)),
+		),
+	}, nil
+}
+
+// Shutdown exports the metrics (once more) and stops exporting
+func (o *OTLP) Shutdown(ctx context.Context) error {
+	log.Println("[OTLP:Shutdown] Entered")
+	return o.provider.Shutdown(ctx)
+}
+
+// newOTLPExporter creates an OTLP/gRPC or OTLP/HTTP exporter
+// Endpoints with a scheme are URLs; otherwise they are host:port
+func newOTLPExporter(ctx context.Context, opts OTLPOptions) (metric.Exporter, error) {
+	url := strings.Contains(opts.Endpoint, "://")
+	switch opts.Protocol {
+	case ProtocolGRPC:
+		options := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithHeaders(opts.Headers),
+		}
+		if url {
+			options = append(options, otlpmetricgrpc.WithEndpointURL(opts.Endpoint))
+		} else {
+			options = append(options, otlpmetricgrpc.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			options = append(options, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, options...)
+	case ProtocolHTTP:
+		options := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithHeaders(opts.Headers),
+		}
+		if url {
+			options = append(options, otlpmetrichttp.WithEndpointURL(opts.Endpoint))
+		} else {
+			options = append(options, otlpmetrichttp.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			options = append(options, otlpmetrichttp.WithInsecure())
+		}
+		return otlpmetrichttp.New(ctx, options...)
+	}
+	return nil, fmt.Errorf("unknown OTLP protocol %q; expected %s or %s", opts.Protocol, ProtocolGRPC, ProtocolHTTP)
+}
@@ -0,0 +1,154 @@
+package push
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// receiver is a stand-in for an OTLP receiver that records the export requests it receives
+type receiver struct {
+	colmetricpb.UnimplementedMetricsServiceServer
+	requests chan *colmetricpb.ExportMetricsServiceRequest
+}
+
+func newReceiver() *receiver {
+	return &receiver{
+		requests: make(chan *colmetricpb.ExportMetricsServiceRequest, 10),
+	}
+}
+
+// Export implements the OTLP/gRPC MetricsService
+func (r *receiver) Export(ctx context.Context, req *colmetricpb.ExportMetricsServiceRequest) (*colmetricpb.ExportMetricsServiceResponse, error) {
+	r.requests <- req
+	return &colmetricpb.ExportMetricsServiceResponse{}, nil
+}
+
+// ServeHTTP implements OTLP/HTTP (protobuf)
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	export := &colmetricpb.ExportMetricsServiceRequest{}
+	if err := proto.Unmarshal(body, export); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.requests <- export
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	b, _ := proto.Marshal(&colmetricpb.ExportMetricsServiceResponse{})
+	w.Write(b)
+}
+
+func TestOTLP(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	balance := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "linode_account_balance",
+		Help: "Balance of account",
+	})
+	balance.Set(42)
+	registry.MustRegister(balance)
+
+	r := newReceiver()
+
+	httpServer := httptest.NewServer(r)
+	defer httpServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	colmetricpb.RegisterMetricsServiceServer(grpcServer, r)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	for _, opts := range []OTLPOptions{
+		{
+			Endpoint: httpServer.URL + "/v1/metrics",
+			Protocol: ProtocolHTTP,
+		},
+		{
+			Endpoint: listener.Addr().String(),
+			Protocol: ProtocolGRPC,
+			Insecure: true,
+		},
+	} {
+		t.Run(opts.Protocol, func(t *testing.T) {
+			opts.Interval = time.Hour
+			opts.Attributes = map[string]string{
+				"service.version": "abc123",
+			}
+			o, err := NewOTLP(t.Context(), registry, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Shutdown exports the metrics (without waiting for the interval)
+			if err := o.Shutdown(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+
+			var req *colmetricpb.ExportMetricsServiceRequest
+			select {
+			case req = <-r.requests:
+			case <-time.After(10 * time.Second):
+				t.Fatal("no export received")
+			}
+
+			if len(req.ResourceMetrics) != 1 {
+				t.Fatalf("got %d resource metrics; want 1", len(req.ResourceMetrics))
+			}
+			rm := req.ResourceMetrics[0]
+			attributes := map[string]string{}
+			for _, kv := range rm.Resource.Attributes {
+				attributes[kv.Key] = kv.Value.GetStringValue()
+			}
+			for k, want := range map[string]string{
+				"service.name":    "linode-exporter",
+				"service.version": "abc123",
+			} {
+				if got := attributes[k]; got != want {
+					t.Errorf("got resource attribute %s=%q; want %q", k, got, want)
+				}
+			}
+
+			var found bool
+			for _, sm := range rm.ScopeMetrics {
+				for _, m := range sm.Metrics {
+					if m.Name != "linode_account_balance" {
+						continue
+					}
+					found = true
+					points := m.GetGauge().GetDataPoints()
+					if len(points) != 1 || points[0].GetAsDouble() != 42 {
+						t.Errorf("got %v; want a single data point of 42", points)
+					}
+				}
+			}
+			if !found {
+				t.Error("linode_account_balance was not exported")
+			}
+		})
+	}
+}
+
+func TestNewOTLPUnknownProtocol(t *testing.T) {
+	if _, err := NewOTLP(t.Context(), prometheus.NewRegistry(), OTLPOptions{
+		Endpoint: "localhost:4317",
+		Protocol: "carrier-pigeon",
+	}); err == nil {
+		t.Error("got nil error for an unknown protocol")
+	}
+}
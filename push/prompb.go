@@ -0,0 +1,157 @@
+package push
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The remote-write (v1) WriteRequest is encoded directly using these field numbers from Prometheus' prompb
+const (
+	// WriteRequest
+	fieldTimeseries = 1
+	fieldMetadata   = 3
+	// TimeSeries
+	fieldLabels  = 1
+	fieldSamples = 2
+	// Label
+	fieldName  = 1
+	fieldValue = 2
+	// Sample
+	fieldSampleValue     = 1
+	fieldSampleTimestamp = 2
+	// MetricMetadata
+	fieldMetadataType   = 1
+	fieldMetadataFamily = 2
+	fieldMetadataHelp   = 4
+)
+
+// MetricMetadata types
+var metadataTypes = map[dto.MetricType]uint64{
+	dto.MetricType_COUNTER:   1,
+	dto.MetricType_GAUGE:     2,
+	dto.MetricType_HISTOGRAM: 3,
+	dto.MetricType_SUMMARY:   5,
+}
+
+// label is a name-value pair of a series
+type label struct {
+	name  string
+	value string
+}
+
+// encodeWriteRequest encodes the metric families as a remote-write WriteRequest, returning it and its number of samples
+// Samples without timestamps are timestamped now; external labels are added to series without them
+func encodeWriteRequest(families []*dto.MetricFamily, external map[string]string, now time.Time) ([]byte, int) {
+	var b []byte
+	samples := 0
+	timestamp := now.UnixMilli()
+	for _, f := range families {
+		name := f.GetName()
+		for _, m := range f.GetMetric() {
+			ts := timestamp
+			if m.TimestampMs != nil {
+				ts = m.GetTimestampMs()
+			}
+			series := func(suffix string, value float64, extra ...label) {
+				labels := make([]label, 0, len(m.GetLabel())+len(extra)+len(external)+1)
+				labels = append(labels, label{name: "__name__", value: name + suffix})
+				for _, l := range m.GetLabel() {
+					labels = append(labels, label{name: l.GetName(), value: l.GetValue()})
+				}
+				labels = append(labels, extra...)
+				b = protowire.AppendTag(b, fieldTimeseries, protowire.BytesType)
+				b = protowire.AppendBytes(b, encodeTimeSeries(labels, external, value, ts))
+				samples++
+			}
+
+			switch f.GetType() {
+			case dto.MetricType_COUNTER:
+				series("", m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				series("", m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				series("", m.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					series("", q.GetValue(), label{name: "quantile", value: formatFloat(q.GetQuantile())})
+				}
+				series("_sum", s.GetSampleSum())
+				series("_count", float64(s.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				inf := false
+				for _, bucket := range h.GetBucket() {
+					inf = inf || math.IsInf(bucket.GetUpperBound(), +1)
+					series("_bucket", float64(bucket.GetCumulativeCount()), label{name: "le", value: formatFloat(bucket.GetUpperBound())})
+				}
+				if !inf {
+					series("_bucket", float64(h.GetSampleCount()), label{name: "le", value: "+Inf"})
+				}
+				series("_sum", h.GetSampleSum())
+				series("_count", float64(h.GetSampleCount()))
+			}
+		}
+
+		if t, ok := metadataTypes[f.GetType()]; ok {
+			var md []byte
+			md = protowire.AppendTag(md, fieldMetadataType, protowire.VarintType)
+			md = protowire.AppendVarint(md, t)
+			md = protowire.AppendTag(md, fieldMetadataFamily, protowire.BytesType)
+			md = protowire.AppendString(md, name)
+			md = protowire.AppendTag(md, fieldMetadataHelp, protowire.BytesType)
+			md = protowire.AppendString(md, f.GetHelp())
+			b = protowire.AppendTag(b, fieldMetadata, protowire.BytesType)
+			b = protowire.AppendBytes(b, md)
+		}
+	}
+	return b, samples
+}
+
+// encodeTimeSeries encodes a TimeSeries of a single sample; its labels are sorted by name
+func encodeTimeSeries(labels []label, external map[string]string, value float64, timestamp int64) []byte {
+	names := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		names[l.name] = true
+	}
+	for name, value := range external {
+		if !names[name] {
+			labels = append(labels, label{name: name, value: value})
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].name < labels[j].name
+	})
+
+	var b []byte
+	for _, l := range labels {
+		var lb []byte
+		lb = protowire.AppendTag(lb, fieldName, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.name)
+		lb = protowire.AppendTag(lb, fieldValue, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.value)
+		b = protowire.AppendTag(b, fieldLabels, protowire.BytesType)
+		b = protowire.AppendBytes(b, lb)
+	}
+	var sb []byte
+	sb = protowire.AppendTag(sb, fieldSampleValue, protowire.Fixed64Type)
+	sb = protowire.AppendFixed64(sb, math.Float64bits(value))
+	sb = protowire.AppendTag(sb, fieldSampleTimestamp, protowire.VarintType)
+	sb = protowire.AppendVarint(sb, uint64(timestamp))
+	b = protowire.AppendTag(b, fieldSamples, protowire.BytesType)
+	b = protowire.AppendBytes(b, sb)
+	return b
+}
+
+// formatFloat formats a quantile or bucket bound as Prometheus does
+func formatFloat(f float64) string {
+	if math.IsInf(f, +1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,322 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/DazWilkin/linode-exporter/transport"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "linode"
+	subsystem = "exporter"
+
+	// Reasons that gathers are not sent
+	reasonGather   = "gather"
+	reasonQueue    = "queue_full"
+	reasonRejected = "rejected"
+	reasonRetries  = "retries_exhausted"
+)
+
+// RemoteWriteOptions configures a RemoteWrite
+type RemoteWriteOptions struct {
+	// URL is the remote-write endpoint, e.g. http://localhost:9090/api/v1/write
+	URL string
+	// Interval is the duration between gathers
+	Interval time.Duration
+	// Timeout bounds each remote-write request; zero is unlimited
+	Timeout time.Duration
+	// MaxRetries is the maximum number of times a request is retried after a network error, 429 or 5xx response
+	MaxRetries int
+	// QueueCapacity is the maximum number of gathers queued for sending; when the queue is full, gathers are dropped
+	QueueCapacity int
+	// Username and Password authenticate requests with basic authentication
+	Username string
+	Password string
+	// BearerToken authenticates requests with a bearer token; it may not be combined with basic authentication
+	BearerToken string
+	// ExternalLabels are added to every series (unless the series has the label)
+	ExternalLabels map[string]string
+	// Client sends requests; if nil, http.DefaultClient is used
+	Client *http.Client
+}
+
+// batch is a (snappy-compressed) remote-write request
+type batch struct {
+	body    []byte
+	samples int
+}
+
+// RemoteWrite periodically gathers metrics and sends them to a Prometheus remote-write endpoint
+// RemoteWrite is also a Collector that reports its queue, requests and failures
+type RemoteWrite struct {
+	gatherer prometheus.Gatherer
+	opts     RemoteWriteOptions
+	client   *http.Client
+	queue    chan batch
+
+	QueueLength   *prometheus.Desc
+	QueueCapacity *prometheus.Desc
+	Requests      *prometheus.CounterVec
+	Retries       prometheus.Counter
+	Samples       prometheus.Counter
+	Failures      *prometheus.CounterVec
+	LastSuccess   prometheus.Gauge
+}
+
+// NewRemoteWrite creates a RemoteWrite that sends the metrics gathered by gatherer once it is Run
+func NewRemoteWrite(gatherer prometheus.Gatherer, opts RemoteWriteOptions) (*RemoteWrite, error) {
+	log.Println("[NewRemoteWrite] Entered")
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("remote-write URL %q must be http or https", opts.URL)
+	}
+	if opts.BearerToken != "" && (opts.Username != "" || opts.Password != "") {
+		return nil, errors.New("remote-write accepts either basic authentication or a bearer token, not both")
+	}
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("invalid remote-write interval %s", opts.Interval)
+	}
+	if opts.QueueCapacity < 1 {
+		opts.QueueCapacity = 1
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &RemoteWrite{
+		gatherer: gatherer,
+		opts:     opts,
+		client:   client,
+		queue:    make(chan batch, opts.QueueCapacity),
+
+		QueueLength: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "remote_write_queue_length"),
+			"Number of gathers queued for sending to the remote-write endpoint",
+			nil,
+			nil,
+		),
+		QueueCapacity: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "remote_write_queue_capacity"),
+			"Maximum number of gathers queued for sending to the remote-write endpoint",
+			nil,
+			nil,
+		),
+		Requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "remote_write_requests_total",
+				Help:      "Number of remote-write requests (including retries) by response status code (or error)",
+			},
+			[]string{"code"},
+		),
+		Retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "remote_write_retries_total",
+			Help:      "Number of remote-write requests retried",
+		}),
+		Samples: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "remote_write_samples_total",
+			Help:      "Number of samples sent to the remote-write endpoint",
+		}),
+		Failures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "remote_write_failures_total",
+				Help:      "Number of gathers not sent to the remote-write endpoint by reason (gather, queue_full, rejected, retries_exhausted)",
+			},
+			[]string{"reason"},
+		),
+		LastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "remote_write_last_success_timestamp_seconds",
+			Help:      "Time at which a remote-write request last succeeded",
+		}),
+	}, nil
+}
+
+// Run gathers every interval and sends the gathered metrics until ctx is done
+// Gathers are queued so that a slow (or failing) endpoint does not delay gathering
+func (r *RemoteWrite) Run(ctx context.Context) {
+	log.Println("[RemoteWrite:Run] Entered")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.send(ctx)
+	}()
+
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+	for {
+		r.gather()
+		select {
+		case <-ctx.Done():
+			<-done
+			log.Println("[RemoteWrite:Run] Completes")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// gather gathers the metrics and queues them for sending
+func (r *RemoteWrite) gather() {
+	families, err := r.gatherer.Gather()
+	if len(families) == 0 && err != nil {
+		log.Printf("[RemoteWrite:gather] unable to gather metrics: %v", err)
+		r.Failures.WithLabelValues(reasonGather).Inc()
+		return
+	}
+	if err != nil {
+		// Gatherers return the metrics they gathered with an error for those they could not
+		log.Printf("[RemoteWrite:gather] gathered with errors: %v", err)
+	}
+
+	body, samples := encodeWriteRequest(families, r.opts.ExternalLabels, time.Now())
+	select {
+	case r.queue <- batch{body: snappy.Encode(nil, body), samples: samples}:
+	default:
+		log.Println("[RemoteWrite:gather] queue is full; dropping gather")
+		r.Failures.WithLabelValues(reasonQueue).Inc()
+	}
+}
+
+// send sends queued batches until ctx is done
+func (r *RemoteWrite) send(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case b := <-r.queue:
+			if reason, err := r.write(ctx, b); err != nil {
+				log.Printf("[RemoteWrite:send] unable to write %d samples: %v", b.samples, err)
+				r.Failures.WithLabelValues(reason).Inc()
+				continue
+			}
+			r.Samples.Add(float64(b.samples))
+			r.LastSuccess.SetToCurrentTime()
+		}
+	}
+}
+
+// write sends a batch, retrying network errors, 429 and 5xx responses with jittered exponential backoff
+// If the batch is not sent, write returns the reason
+func (r *RemoteWrite) write(ctx context.Context, b batch) (string, error) {
+	for attempt := 0; ; attempt++ {
+		code, err := r.post(ctx, b)
+		if err == nil {
+			return "", nil
+		}
+		if ctx.Err() != nil {
+			return reasonRetries, err
+		}
+		if code != 0 && !transport.Retryable(code) {
+			return reasonRejected, err
+		}
+		if attempt >= r.opts.MaxRetries {
+			return reasonRetries, err
+		}
+
+		wait := transport.Backoff(attempt)
+		log.Printf("[RemoteWrite:write] %v; retrying in %s", err, wait)
+		r.Retries.Inc()
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return reasonRetries, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// post sends a batch once, returning the response's status code (zero for network errors)
+func (r *RemoteWrite) post(ctx context.Context, b batch) (int, error) {
+	if r.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.opts.URL, bytes.NewReader(b.body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "linode-exporter")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	switch {
+	case r.opts.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+r.opts.BearerToken)
+	case r.opts.Username != "" || r.opts.Password != "":
+		req.SetBasicAuth(r.opts.Username, r.opts.Password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		r.Requests.WithLabelValues("error").Inc()
+		return 0, err
+	}
+	defer resp.Body.Close()
+	r.Requests.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	// The response body (if any) explains the failure
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	return resp.StatusCode, fmt.Errorf("remote-write returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+}
+
+// Collect implements Collector interface and is called by Prometheus to collect metrics
+func (r *RemoteWrite) Collect(ch chan<- prometheus.Metric) {
+	ch <- newConstMetric(r.QueueLength, prometheus.GaugeValue, float64(len(r.queue)))
+	ch <- newConstMetric(r.QueueCapacity, prometheus.GaugeValue, float64(cap(r.queue)))
+	r.Requests.Collect(ch)
+	r.Retries.Collect(ch)
+	r.Samples.Collect(ch)
+	r.Failures.Collect(ch)
+	r.LastSuccess.Collect(ch)
+}
+
+// newConstMetric is prometheus.MustNewConstMetric except that, rather than panicking,
+// it returns an invalid metric if the metric cannot be constructed
+func newConstMetric(desc *prometheus.Desc, valueType prometheus.ValueType, value float64, labelValues ...string) prometheus.Metric {
+	m, err := prometheus.NewConstMetric(desc, valueType, value, labelValues...)
+	if err != nil {
+		return prometheus.NewInvalidMetric(desc, err)
+	}
+	return m
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (r *RemoteWrite) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.QueueLength
+	ch <- r.QueueCapacity
+	r.Requests.Describe(ch)
+	r.Retries.Describe(ch)
+	r.Samples.Describe(ch)
+	r.Failures.Describe(ch)
+	r.LastSuccess.Describe(ch)
+}
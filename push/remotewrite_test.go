@@ -0,0 +1,430 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// sample is a decoded remote-write series (of a single sample)
+type sample struct {
+	labels    map[string]string
+	value     float64
+	timestamp int64
+}
+
+// metadata is a decoded remote-write MetricMetadata
+type metadata struct {
+	typ  string
+	help string
+}
+
+// writeReceiver is a stand-in for a remote-write endpoint that responds with statuses (and then 204s)
+type writeReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	samples  []sample
+	metadata map[string]metadata
+}
+
+func (w *writeReceiver) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.requests = append(w.requests, r)
+	if len(w.statuses) > 0 {
+		status := w.statuses[0]
+		w.statuses = w.statuses[1:]
+		http.Error(rw, http.StatusText(status), status)
+		return
+	}
+	compressed, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.samples, w.metadata, err = decodeWriteRequest(body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// writeRequest is the remote-write (v1) WriteRequest as declared by Prometheus' prompb (remote.proto and types.proto)
+// It is declared independently of the encoder (prompb.go) so that the encoding is checked against the schema
+var writeRequest = func() protoreflect.MessageDescriptor {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(".prometheus." + typeName)
+		}
+		return f
+	}
+	repeated := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		return f
+	}
+	message := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	}
+	var values []*descriptorpb.EnumValueDescriptorProto
+	for i, name := range []string{"UNKNOWN", "COUNTER", "GAUGE", "HISTOGRAM", "GAUGEHISTOGRAM", "SUMMARY", "INFO", "STATESET"} {
+		values = append(values, &descriptorpb.EnumValueDescriptorProto{Name: proto.String(name), Number: proto.Int32(int32(i))})
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("prometheus/remote.proto"),
+		Package: proto.String("prometheus"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("WriteRequest",
+				repeated(field("timeseries", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, "TimeSeries")),
+				repeated(field("metadata", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, "MetricMetadata")),
+			),
+			message("TimeSeries",
+				repeated(field("labels", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, "Label")),
+				repeated(field("samples", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, "Sample")),
+			),
+			message("Label",
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+			),
+			message("Sample",
+				field("value", 1, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, ""),
+				field("timestamp", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+			),
+			{
+				Name: proto.String("MetricMetadata"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("type", 1, descriptorpb.FieldDescriptorProto_TYPE_ENUM, "MetricMetadata.MetricType"),
+					field("metric_family_name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("help", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("unit", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				},
+				EnumType: []*descriptorpb.EnumDescriptorProto{
+					{Name: proto.String("MetricType"), Value: values},
+				},
+			},
+		},
+	}, nil)
+	if err != nil {
+		panic(err)
+	}
+	return file.Messages().ByName("WriteRequest")
+}()
+
+// decodeWriteRequest decodes the time series and metadata (by family name) of a WriteRequest
+// Fields that do not match the schema (by number or wire type) are an error
+func decodeWriteRequest(b []byte) ([]sample, map[string]metadata, error) {
+	m := dynamicpb.NewMessage(writeRequest)
+	if err := proto.Unmarshal(b, m); err != nil {
+		return nil, nil, err
+	}
+	if err := checkUnknown(m); err != nil {
+		return nil, nil, err
+	}
+
+	var samples []sample
+	series := m.Get(writeRequest.Fields().ByName("timeseries")).List()
+	for i := 0; i < series.Len(); i++ {
+		ts := series.Get(i).Message()
+		s := sample{labels: map[string]string{}}
+		labels := ts.Get(ts.Descriptor().Fields().ByName("labels")).List()
+		for j := 0; j < labels.Len(); j++ {
+			l := labels.Get(j).Message()
+			fields := l.Descriptor().Fields()
+			s.labels[l.Get(fields.ByName("name")).String()] = l.Get(fields.ByName("value")).String()
+		}
+		ss := ts.Get(ts.Descriptor().Fields().ByName("samples")).List()
+		if ss.Len() != 1 {
+			return nil, nil, fmt.Errorf("got %d samples for %v; want 1", ss.Len(), s.labels)
+		}
+		sm := ss.Get(0).Message()
+		fields := sm.Descriptor().Fields()
+		s.value = sm.Get(fields.ByName("value")).Float()
+		s.timestamp = sm.Get(fields.ByName("timestamp")).Int()
+		samples = append(samples, s)
+	}
+
+	families := map[string]metadata{}
+	mds := m.Get(writeRequest.Fields().ByName("metadata")).List()
+	for i := 0; i < mds.Len(); i++ {
+		md := mds.Get(i).Message()
+		fields := md.Descriptor().Fields()
+		typ := fields.ByName("type")
+		families[md.Get(fields.ByName("metric_family_name")).String()] = metadata{
+			typ:  string(typ.Enum().Values().ByNumber(md.Get(typ).Enum()).Name()),
+			help: md.Get(fields.ByName("help")).String(),
+		}
+	}
+	return samples, families, nil
+}
+
+// checkUnknown returns an error if m (or any message within it) has unknown fields
+func checkUnknown(m protoreflect.Message) error {
+	if len(m.GetUnknown()) > 0 {
+		return fmt.Errorf("%s has unknown fields", m.Descriptor().Name())
+	}
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil {
+			return true
+		}
+		if fd.IsList() {
+			for i := 0; i < v.List().Len() && err == nil; i++ {
+				err = checkUnknown(v.List().Get(i).Message())
+			}
+		} else {
+			err = checkUnknown(v.Message())
+		}
+		return err == nil
+	})
+	return err
+}
+
+func newTestRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	balance := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "linode_account_balance",
+		Help:        "Balance of account",
+		ConstLabels: prometheus.Labels{"region": "us-east"},
+	})
+	balance.Set(42)
+	duration := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "linode_exporter_http_client_request_duration_seconds",
+		Help:    "Latency of Linode API (HTTP) requests",
+		Buckets: []float64{0.1, 1},
+	})
+	duration.Observe(0.5)
+	registry.MustRegister(balance, duration)
+	return registry
+}
+
+func TestRemoteWrite(t *testing.T) {
+	receiver := &writeReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	r, err := NewRemoteWrite(newTestRegistry(), RemoteWriteOptions{
+		URL:      server.URL + "/api/v1/write",
+		Interval: time.Hour,
+		Username: "user",
+		Password: "pass",
+		ExternalLabels: map[string]string{
+			"site":   "edge-1",
+			"region": "ignored",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+	// Run gathers (and sends) immediately
+	deadline := time.Now().Add(10 * time.Second)
+	for testutil.ToFloat64(r.Samples) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if len(receiver.requests) != 1 {
+		t.Fatalf("got %d requests; want 1", len(receiver.requests))
+	}
+	req := receiver.requests[0]
+	for name, want := range map[string]string{
+		"Content-Encoding":                  "snappy",
+		"Content-Type":                      "application/x-protobuf",
+		"X-Prometheus-Remote-Write-Version": "0.1.0",
+	} {
+		if got := req.Header.Get(name); got != want {
+			t.Errorf("got %s: %q; want %q", name, got, want)
+		}
+	}
+	if username, password, ok := req.BasicAuth(); !ok || username != "user" || password != "pass" {
+		t.Errorf("got basic auth %q:%q (%t); want user:pass", username, password, ok)
+	}
+
+	// 1 gauge and 3 buckets (including +Inf), sum and count of the histogram
+	if got := len(receiver.samples); got != 6 {
+		t.Errorf("got %d samples; want 6", got)
+	}
+	if got := testutil.ToFloat64(r.Samples); got != 6 {
+		t.Errorf("got %v samples sent; want 6", got)
+	}
+	buckets := map[string]float64{}
+	for _, s := range receiver.samples {
+		if s.labels["site"] != "edge-1" {
+			t.Errorf("got site=%q for %s; want edge-1", s.labels["site"], s.labels["__name__"])
+		}
+		if s.timestamp == 0 {
+			t.Errorf("got no timestamp for %s", s.labels["__name__"])
+		}
+		switch s.labels["__name__"] {
+		case "linode_account_balance":
+			// The series' own labels take precedence over external labels
+			if s.value != 42 || s.labels["region"] != "us-east" {
+				t.Errorf("got %v %v; want 42 with region=us-east", s.labels, s.value)
+			}
+		case "linode_exporter_http_client_request_duration_seconds_bucket":
+			buckets[s.labels["le"]] = s.value
+		}
+	}
+	for le, want := range map[string]float64{"0.1": 0, "1": 1, "+Inf": 1} {
+		if got, ok := buckets[le]; !ok || got != want {
+			t.Errorf("got bucket le=%s %v (%t); want %v", le, got, ok, want)
+		}
+	}
+	for name, want := range map[string]metadata{
+		"linode_account_balance":                               {typ: "GAUGE", help: "Balance of account"},
+		"linode_exporter_http_client_request_duration_seconds": {typ: "HISTOGRAM", help: "Latency of Linode API (HTTP) requests"},
+	} {
+		if got := receiver.metadata[name]; got != want {
+			t.Errorf("got metadata %+v for %s; want %+v", got, name, want)
+		}
+	}
+}
+
+func TestRemoteWriteRetries(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		statuses []int
+		requests int
+		retries  float64
+		reason   string
+	}{
+		{
+			name:     "retried",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			requests: 3,
+			retries:  2,
+		},
+		{
+			name:     "rejected",
+			statuses: []int{http.StatusBadRequest},
+			requests: 1,
+			reason:   reasonRejected,
+		},
+		{
+			name:     "retries exhausted",
+			statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			requests: 2,
+			retries:  1,
+			reason:   reasonRetries,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			receiver := &writeReceiver{statuses: test.statuses}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			maxRetries := 2
+			if test.reason == reasonRetries {
+				maxRetries = 1
+			}
+			r, err := NewRemoteWrite(newTestRegistry(), RemoteWriteOptions{
+				URL:         server.URL,
+				Interval:    time.Hour,
+				MaxRetries:  maxRetries,
+				BearerToken: "token",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.gather()
+			if got := testutil.ToFloat64(r.Failures.WithLabelValues(reasonQueue)); got != 0 {
+				t.Fatalf("got %v queue_full failures; want 0", got)
+			}
+			reason, err := r.write(t.Context(), <-r.queue)
+			if reason != test.reason || (err == nil) != (test.reason == "") {
+				t.Errorf("got %q (%v); want %q", reason, err, test.reason)
+			}
+
+			receiver.mu.Lock()
+			defer receiver.mu.Unlock()
+			if got := len(receiver.requests); got != test.requests {
+				t.Errorf("got %d requests; want %d", got, test.requests)
+			}
+			if got := receiver.requests[0].Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("got Authorization: %q; want \"Bearer token\"", got)
+			}
+			if got := testutil.ToFloat64(r.Retries); got != test.retries {
+				t.Errorf("got %v retries; want %v", got, test.retries)
+			}
+		})
+	}
+}
+
+func TestRemoteWriteQueueFull(t *testing.T) {
+	r, err := NewRemoteWrite(newTestRegistry(), RemoteWriteOptions{
+		URL:           "http://localhost:9090/api/v1/write",
+		Interval:      time.Hour,
+		QueueCapacity: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without a sender, the second gather is dropped
+	r.gather()
+	r.gather()
+	if got := testutil.ToFloat64(r.Failures.WithLabelValues(reasonQueue)); got != 1 {
+		t.Errorf("got %v queue_full failures; want 1", got)
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(r)
+	want := `
+# HELP linode_exporter_remote_write_queue_capacity Maximum number of gathers queued for sending to the remote-write endpoint
+# TYPE linode_exporter_remote_write_queue_capacity gauge
+linode_exporter_remote_write_queue_capacity 1
+# HELP linode_exporter_remote_write_queue_length Number of gathers queued for sending to the remote-write endpoint
+# TYPE linode_exporter_remote_write_queue_length gauge
+linode_exporter_remote_write_queue_length 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want), "linode_exporter_remote_write_queue_capacity", "linode_exporter_remote_write_queue_length"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewRemoteWrite(t *testing.T) {
+	for name, opts := range map[string]RemoteWriteOptions{
+		"scheme":   {URL: "localhost:9090", Interval: time.Minute},
+		"auth":     {URL: "http://localhost:9090", Interval: time.Minute, Username: "user", BearerToken: "token"},
+		"interval": {URL: "http://localhost:9090"},
+	} {
+		if _, err := NewRemoteWrite(prometheus.NewRegistry(), opts); err == nil {
+			t.Errorf("%s: got nil error", name)
+		}
+	}
+}
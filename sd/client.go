@@ -0,0 +1,43 @@
+package sd
+
+import (
+	"context"
+
+	"github.com/linode/linodego"
+)
+
+// Service discovery depends upon these (narrow) subsets of the Linode API client and of the collectors' inventory
+// *linodego.Client and *collector.Inventory satisfy them respectively
+
+// LKENodePoolLister lists an LKE cluster's node pools
+type LKENodePoolLister interface {
+	ListLKENodePools(ctx context.Context, clusterID int, opts *linodego.ListOptions) ([]linodego.LKENodePool, error)
+}
+
+// NodeBalancerClient lists NodeBalancers' configs and their (backend) nodes
+type NodeBalancerClient interface {
+	ListNodeBalancerConfigs(ctx context.Context, nodebalancerID int, opts *linodego.ListOptions) ([]linodego.NodeBalancerConfig, error)
+	ListNodeBalancerNodes(ctx context.Context, nodebalancerID int, configID int, opts *linodego.ListOptions) ([]linodego.NodeBalancerNode, error)
+}
+
+// InstanceLister lists Linodes
+// Using the collectors' inventory, targets are discovered from the (filtered) resources the collectors use
+type InstanceLister interface {
+	Instances(ctx context.Context) ([]linodego.Instance, error)
+}
+
+// LKEInventory lists LKE clusters and Linodes
+type LKEInventory interface {
+	InstanceLister
+	LKEClusters(ctx context.Context) ([]linodego.LKECluster, error)
+}
+
+// NodeBalancerLister lists NodeBalancers
+type NodeBalancerLister interface {
+	NodeBalancers(ctx context.Context) ([]linodego.NodeBalancer, error)
+}
+
+var (
+	_ LKENodePoolLister  = (*linodego.Client)(nil)
+	_ NodeBalancerClient = (*linodego.Client)(nil)
+)
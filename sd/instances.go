@@ -0,0 +1,116 @@
+package sd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/linode/linodego"
+)
+
+// Address is the address of a Linode that is used as its target
+type Address string
+
+const (
+	// PublicIPv4 is the Linode's (first) public IPv4 address
+	PublicIPv4 Address = "public_ipv4"
+	// PrivateIPv4 is the Linode's (first) private IPv4 address
+	PrivateIPv4 Address = "private_ipv4"
+	// PublicIPv6 is the Linode's (SLAAC) IPv6 address
+	PublicIPv6 Address = "public_ipv6"
+)
+
+// ParseAddress parses an Address
+func ParseAddress(s string) (Address, error) {
+	switch a := Address(s); a {
+	case PublicIPv4, PrivateIPv4, PublicIPv6:
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown address %q; expected one of %s, %s or %s", s, PublicIPv4, PrivateIPv4, PublicIPv6)
+}
+
+// Instances returns a target group for each Linode with the address at port
+// Linodes without the address are omitted
+func Instances(ctx context.Context, lister InstanceLister, address Address, port int) ([]TargetGroup, error) {
+	if err := checkPort(port); err != nil {
+		return nil, err
+	}
+	instances, err := lister.Instances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]TargetGroup, 0, len(instances))
+	for _, i := range instances {
+		if g, ok := instanceTargetGroup(i, address, port); ok {
+			groups = append(groups, g)
+		}
+	}
+	return groups, nil
+}
+
+// NewInstancesHandler creates an http.Handler that serves the target groups of the Linodes (see Instances)
+func NewInstancesHandler(lister InstanceLister, address Address, port int) http.Handler {
+	return handler("instances", func(ctx context.Context) ([]TargetGroup, error) {
+		return Instances(ctx, lister, address, port)
+	})
+}
+
+// instanceTargetGroup returns the target group of a Linode with the address at port, if the Linode has the address
+func instanceTargetGroup(i linodego.Instance, address Address, port int) (TargetGroup, bool) {
+	addresses := instanceAddresses(i)
+	host := addresses[address]
+	if host == "" {
+		return TargetGroup{}, false
+	}
+	return TargetGroup{
+		Targets: []string{net.JoinHostPort(host, strconv.Itoa(port))},
+		Labels:  instanceLabels(i, addresses),
+	}, true
+}
+
+// instanceAddresses returns the Linode's (first) public and private IPv4 addresses and its IPv6 address
+func instanceAddresses(i linodego.Instance) map[Address]string {
+	addresses := map[Address]string{}
+	for _, ip := range i.IPv4 {
+		if ip == nil {
+			continue
+		}
+		a := PublicIPv4
+		if ip.IsPrivate() {
+			a = PrivateIPv4
+		}
+		if _, ok := addresses[a]; !ok {
+			addresses[a] = ip.String()
+		}
+	}
+	// The IPv6 address is returned with its prefix length, e.g. 2001:db8::1/128
+	if ip, _, _ := strings.Cut(i.IPv6, "/"); ip != "" {
+		addresses[PublicIPv6] = ip
+	}
+	return addresses
+}
+
+// instanceLabels returns the meta labels of a Linode
+// The names are consistent with Prometheus' own Linode service discovery (linode_sd_configs)
+func instanceLabels(i linodego.Instance, addresses map[Address]string) map[string]string {
+	labels := map[string]string{
+		metaPrefix + "instance_id":    strconv.Itoa(i.ID),
+		metaPrefix + "instance_label": i.Label,
+		metaPrefix + "region":         i.Region,
+		metaPrefix + "type":           i.Type,
+		metaPrefix + "status":         string(i.Status),
+		metaPrefix + "tags":           tags(i.Tags),
+		metaPrefix + "lke_cluster_id": "",
+	}
+	if i.LKEClusterID != 0 {
+		labels[metaPrefix+"lke_cluster_id"] = strconv.Itoa(i.LKEClusterID)
+	}
+	for a, ip := range addresses {
+		labels[metaPrefix+string(a)] = ip
+	}
+	return labels
+}
@@ -0,0 +1,113 @@
+package sd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/linode-exporter/collector"
+	"github.com/DazWilkin/linode-exporter/linodefake"
+)
+
+func TestInstancesHandler(t *testing.T) {
+	server := linodefake.NewServer(linodefake.Fixtures())
+	defer server.Close()
+	inventory := collector.NewInventory(server.Client(), nil, time.Minute)
+
+	for _, test := range []struct {
+		address Address
+		want    map[string]string
+	}{
+		{
+			address: PublicIPv4,
+			want: map[string]string{
+				"123":  "192.0.2.10:9100",
+				"456":  "192.0.2.20:9100",
+				"5001": "192.0.2.51:9100",
+				"5002": "192.0.2.52:9100",
+			},
+		},
+		{
+			// db-1 (456) has no private IPv4 address
+			address: PrivateIPv4,
+			want: map[string]string{
+				"123":  "192.168.128.10:9100",
+				"5001": "192.168.128.51:9100",
+				"5002": "192.168.128.52:9100",
+			},
+		},
+		{
+			address: PublicIPv6,
+			want: map[string]string{
+				"123":  "[2001:db8::10]:9100",
+				"456":  "[2001:db8::20]:9100",
+				"5001": "[2001:db8::51]:9100",
+				"5002": "[2001:db8::52]:9100",
+			},
+		},
+	} {
+		t.Run(string(test.address), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			NewInstancesHandler(inventory, test.address, 9100).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sd/instances", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d; want %d", w.Code, http.StatusOK)
+			}
+			var groups []TargetGroup
+			if err := json.Unmarshal(w.Body.Bytes(), &groups); err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]string{}
+			for _, g := range groups {
+				if len(g.Targets) != 1 {
+					t.Fatalf("got %d targets; want 1", len(g.Targets))
+				}
+				got[g.Labels["__meta_linode_instance_id"]] = g.Targets[0]
+			}
+			if len(got) != len(test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+			for id, target := range test.want {
+				if got[id] != target {
+					t.Errorf("got target %q for %s; want %q", got[id], id, target)
+				}
+			}
+		})
+	}
+
+	groups, err := Instances(t.Context(), inventory, PublicIPv4, 9100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range groups {
+		switch g.Labels["__meta_linode_instance_id"] {
+		case "123":
+			for name, want := range map[string]string{
+				"__meta_linode_instance_label": "web-1",
+				"__meta_linode_region":         "us-east",
+				"__meta_linode_type":           "g6-standard-2",
+				"__meta_linode_status":         "running",
+				"__meta_linode_tags":           ",env:prod,web,",
+				"__meta_linode_private_ipv4":   "192.168.128.10",
+				"__meta_linode_lke_cluster_id": "",
+			} {
+				if got := g.Labels[name]; got != want {
+					t.Errorf("got %s=%q; want %q", name, got, want)
+				}
+			}
+		case "5001":
+			if got := g.Labels["__meta_linode_lke_cluster_id"]; got != "3001" {
+				t.Errorf("got __meta_linode_lke_cluster_id=%q; want \"3001\"", got)
+			}
+		}
+	}
+
+	// The Linode API's errors are served as 500s (so that Prometheus retains its targets)
+	server.Fail("linode/instances", http.StatusServiceUnavailable)
+	w := httptest.NewRecorder()
+	NewInstancesHandler(collector.NewInventory(server.Client(), nil, 0), PublicIPv4, 9100).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sd/instances", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d; want %d", w.Code, http.StatusInternalServerError)
+	}
+}
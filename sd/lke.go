@@ -0,0 +1,63 @@
+package sd
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// LKENodes returns a target group for each node of each LKE cluster with the address (of its Linode) at port
+// Nodes whose Linodes are not listed (e.g. because they are excluded by the inventory's filter) or lack the address are omitted
+func LKENodes(ctx context.Context, inventory LKEInventory, client LKENodePoolLister, address Address, port int) ([]TargetGroup, error) {
+	if err := checkPort(port); err != nil {
+		return nil, err
+	}
+	clusters, err := inventory.LKEClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := inventory.Instances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[int]int, len(instances))
+	for n, i := range instances {
+		index[i.ID] = n
+	}
+
+	var groups []TargetGroup
+	for _, c := range clusters {
+		pools, err := client.ListLKENodePools(ctx, c.ID, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pools {
+			for _, node := range p.Linodes {
+				n, ok := index[node.InstanceID]
+				if !ok {
+					log.Printf("[sd:LKENodes] LKE node %s (Linode %d) not listed", node.ID, node.InstanceID)
+					continue
+				}
+				g, ok := instanceTargetGroup(instances[n], address, port)
+				if !ok {
+					continue
+				}
+				g.Labels[metaPrefix+"lke_cluster_id"] = strconv.Itoa(c.ID)
+				g.Labels[metaPrefix+"lke_cluster_label"] = c.Label
+				g.Labels[metaPrefix+"lke_pool_id"] = strconv.Itoa(p.ID)
+				g.Labels[metaPrefix+"lke_node_id"] = node.ID
+				g.Labels[metaPrefix+"lke_node_status"] = string(node.Status)
+				groups = append(groups, g)
+			}
+		}
+	}
+	return groups, nil
+}
+
+// NewLKEHandler creates an http.Handler that serves the target groups of the LKE clusters' nodes (see LKENodes)
+func NewLKEHandler(inventory LKEInventory, client LKENodePoolLister, address Address, port int) http.Handler {
+	return handler("lke", func(ctx context.Context) ([]TargetGroup, error) {
+		return LKENodes(ctx, inventory, client, address, port)
+	})
+}
@@ -0,0 +1,79 @@
+package sd
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/linode-exporter/collector"
+	"github.com/DazWilkin/linode-exporter/linodefake"
+)
+
+func TestLKENodes(t *testing.T) {
+	server := linodefake.NewServer(linodefake.Fixtures())
+	defer server.Close()
+	client := server.Client()
+
+	groups, err := LKENodes(t.Context(), collector.NewInventory(client, nil, time.Minute), client, PrivateIPv4, 9100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("got %d target groups; want 2", len(groups))
+	}
+	for _, test := range []struct {
+		target string
+		labels map[string]string
+	}{
+		{
+			target: "192.168.128.51:9100",
+			labels: map[string]string{
+				"__meta_linode_instance_id":       "5001",
+				"__meta_linode_lke_cluster_id":    "3001",
+				"__meta_linode_lke_cluster_label": "cluster-1",
+				"__meta_linode_lke_pool_id":       "4001",
+				"__meta_linode_lke_node_id":       "4001-aaaa",
+				"__meta_linode_lke_node_status":   "ready",
+			},
+		},
+		{
+			target: "192.168.128.52:9100",
+			labels: map[string]string{
+				"__meta_linode_instance_id":     "5002",
+				"__meta_linode_lke_node_id":     "4001-bbbb",
+				"__meta_linode_lke_node_status": "not_ready",
+			},
+		},
+	} {
+		g := find(groups, test.target)
+		if g == nil {
+			t.Errorf("no target group for %s", test.target)
+			continue
+		}
+		for name, want := range test.labels {
+			if got := g.Labels[name]; got != want {
+				t.Errorf("got %s=%q for %s; want %q", name, got, test.target, want)
+			}
+		}
+	}
+
+	// LKE nodes whose Linodes are filtered out are omitted
+	filter := &collector.Filter{ExcludeIDs: []int{5002}}
+	groups, err = LKENodes(t.Context(), collector.NewInventory(client, filter, time.Minute), client, PrivateIPv4, 9100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 {
+		t.Errorf("got %d target groups; want 1", len(groups))
+	}
+}
+
+// find returns the target group with target, if any
+func find(groups []TargetGroup, target string) *TargetGroup {
+	for i, g := range groups {
+		if slices.Contains(g.Targets, target) {
+			return &groups[i]
+		}
+	}
+	return nil
+}
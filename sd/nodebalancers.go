@@ -0,0 +1,60 @@
+package sd
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// NodeBalancerNodes returns a target group for each backend node of each NodeBalancer's configs
+// Each node's target is its address (and port) as configured on the NodeBalancer
+func NodeBalancerNodes(ctx context.Context, lister NodeBalancerLister, client NodeBalancerClient) ([]TargetGroup, error) {
+	nodebalancers, err := lister.NodeBalancers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []TargetGroup
+	for _, nb := range nodebalancers {
+		label := ""
+		if nb.Label != nil {
+			label = *nb.Label
+		}
+		configs, err := client.ListNodeBalancerConfigs(ctx, nb.ID, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range configs {
+			nodes, err := client.ListNodeBalancerNodes(ctx, nb.ID, c.ID, nil)
+			if err != nil {
+				return nil, err
+			}
+			for _, node := range nodes {
+				groups = append(groups, TargetGroup{
+					Targets: []string{node.Address},
+					Labels: map[string]string{
+						metaPrefix + "nodebalancer_id":    strconv.Itoa(nb.ID),
+						metaPrefix + "nodebalancer_label": label,
+						metaPrefix + "region":             nb.Region,
+						metaPrefix + "tags":               tags(nb.Tags),
+						metaPrefix + "config_id":          strconv.Itoa(c.ID),
+						metaPrefix + "config_port":        strconv.Itoa(c.Port),
+						metaPrefix + "config_protocol":    string(c.Protocol),
+						metaPrefix + "node_id":            strconv.Itoa(node.ID),
+						metaPrefix + "node_label":         node.Label,
+						metaPrefix + "node_status":        node.Status,
+						metaPrefix + "node_mode":          string(node.Mode),
+					},
+				})
+			}
+		}
+	}
+	return groups, nil
+}
+
+// NewNodeBalancersHandler creates an http.Handler that serves the target groups of the NodeBalancers' nodes (see NodeBalancerNodes)
+func NewNodeBalancersHandler(lister NodeBalancerLister, client NodeBalancerClient) http.Handler {
+	return handler("nodebalancers", func(ctx context.Context) ([]TargetGroup, error) {
+		return NodeBalancerNodes(ctx, lister, client)
+	})
+}
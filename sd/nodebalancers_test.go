@@ -0,0 +1,68 @@
+package sd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/linode-exporter/collector"
+	"github.com/DazWilkin/linode-exporter/linodefake"
+)
+
+func TestNodeBalancersHandler(t *testing.T) {
+	server := linodefake.NewServer(linodefake.Fixtures())
+	defer server.Close()
+	client := server.Client()
+
+	w := httptest.NewRecorder()
+	NewNodeBalancersHandler(collector.NewInventory(client, nil, time.Minute), client).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sd/nodebalancers", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d; want %d", w.Code, http.StatusOK)
+	}
+	var groups []TargetGroup
+	if err := json.Unmarshal(w.Body.Bytes(), &groups); err != nil {
+		t.Fatal(err)
+	}
+	// lb-1 (2001) has one config with two nodes; 2002 has no configs
+	if len(groups) != 2 {
+		t.Fatalf("got %d target groups; want 2", len(groups))
+	}
+	for target, labels := range map[string]map[string]string{
+		"192.168.128.10:80": {
+			"__meta_linode_nodebalancer_id":    "2001",
+			"__meta_linode_nodebalancer_label": "lb-1",
+			"__meta_linode_region":             "us-east",
+			"__meta_linode_config_id":          "9001",
+			"__meta_linode_config_port":        "80",
+			"__meta_linode_config_protocol":    "http",
+			"__meta_linode_node_id":            "9101",
+			"__meta_linode_node_label":         "web-1",
+			"__meta_linode_node_status":        "UP",
+			"__meta_linode_node_mode":          "accept",
+		},
+		"192.168.128.51:80": {
+			"__meta_linode_node_id":     "9102",
+			"__meta_linode_node_status": "DOWN",
+			"__meta_linode_node_mode":   "drain",
+		},
+	} {
+		g := find(groups, target)
+		if g == nil {
+			t.Errorf("no target group for %s", target)
+			continue
+		}
+		for name, want := range labels {
+			if got := g.Labels[name]; got != want {
+				t.Errorf("got %s=%q for %s; want %q", name, got, target, want)
+			}
+		}
+	}
+
+	// A failure to list a NodeBalancer's nodes fails discovery (rather than dropping the nodes)
+	server.Fail("nodebalancers/2001/configs/9001/nodes", http.StatusInternalServerError)
+	if _, err := NodeBalancerNodes(t.Context(), collector.NewInventory(client, nil, time.Minute), client); err == nil {
+		t.Error("got nil error; want an error")
+	}
+}
@@ -0,0 +1,61 @@
+// Package sd serves Linode resources as Prometheus HTTP service discovery (http_sd_configs) targets
+package sd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	metaPrefix = "__meta_linode_"
+)
+
+// TargetGroup is a Prometheus HTTP service discovery target group
+type TargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// discoverer returns the target groups for a request
+type discoverer func(ctx context.Context) ([]TargetGroup, error)
+
+// handler serves the target groups returned by discover as JSON
+// Errors are served as 500s so that Prometheus retains the targets it discovered previously
+func handler(name string, discover discoverer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		groups, err := discover(r.Context())
+		if err != nil {
+			log.Printf("[sd:%s] unable to discover targets: %v", name, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Prometheus expects an array (rather than null) when there are no targets
+		if groups == nil {
+			groups = []TargetGroup{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(groups); err != nil {
+			log.Printf("[sd:%s] unable to encode targets: %v", name, err)
+		}
+	})
+}
+
+// tags formats tags as Prometheus' service discovery does: joined and surrounded by commas
+func tags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "," + strings.Join(tags, ",") + ","
+}
+
+// checkPort returns an error if port is not a valid TCP port
+func checkPort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port %d", port)
+	}
+	return nil
+}
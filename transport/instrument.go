@@ -0,0 +1,245 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// endpointKey is the context key for a request's (normalised) endpoint
+type endpointKey struct{}
+
+var (
+	// version matches the API version prefix of a Linode API path, e.g. /v4 or /v4beta
+	version = regexp.MustCompile(`^/v\d+[a-z]*`)
+)
+
+// identifiers maps collections whose members are identified by (non-numeric) names onto the placeholders for these names
+var identifiers = map[string][]string{
+	"buckets": {"{region}", "{label}"},
+	"nodes":   {"{id}"},
+	"quotas":  {"{id}"},
+	"tags":    {"{label}"},
+	"users":   {"{username}"},
+}
+
+// resources are the (static) segments of Linode API paths, i.e. collections and their sub-resources
+// Other (non-numeric) segments are identifiers
+var resources = map[string]bool{
+	"access":         true,
+	"account":        true,
+	"api-endpoints":  true,
+	"backups":        true,
+	"clusters":       true,
+	"configs":        true,
+	"dashboard":      true,
+	"databases":      true,
+	"disks":          true,
+	"domains":        true,
+	"endpoints":      true,
+	"events":         true,
+	"firewalls":      true,
+	"grants":         true,
+	"images":         true,
+	"instances":      true,
+	"invoices":       true,
+	"ips":            true,
+	"keys":           true,
+	"kubeconfig":     true,
+	"linode":         true,
+	"lke":            true,
+	"networking":     true,
+	"nodebalancers":  true,
+	"notifications":  true,
+	"object-acl":     true,
+	"object-list":    true,
+	"object-storage": true,
+	"payments":       true,
+	"pools":          true,
+	"profile":        true,
+	"records":        true,
+	"regions":        true,
+	"replies":        true,
+	"settings":       true,
+	"ssl":            true,
+	"stats":          true,
+	"support":        true,
+	"tickets":        true,
+	"transfer":       true,
+	"types":          true,
+	"usage":          true,
+	"versions":       true,
+	"volumes":        true,
+	"vpcs":           true,
+}
+
+// Instrumented is an http.RoundTripper that instruments Linode API requests by (normalised) endpoint
+// Instrumented is also a Collector that reports the request count, latency, in-flight requests and bytes received
+type Instrumented struct {
+	next http.RoundTripper
+
+	Requests *prometheus.CounterVec
+	Duration *prometheus.HistogramVec
+	InFlight prometheus.Gauge
+	Bytes    *prometheus.CounterVec
+}
+
+// NewInstrumented creates an Instrumented wrapping next
+func NewInstrumented(next http.RoundTripper) *Instrumented {
+	log.Println("[NewInstrumented] Entered")
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	i := &Instrumented{
+		Requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "http_client_requests_total",
+				Help:      "Number of Linode API (HTTP) requests by endpoint, method and status code",
+			},
+			[]string{"endpoint", "method", "code"},
+		),
+		Duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "http_client_request_duration_seconds",
+				Help:      "Latency of Linode API (HTTP) requests by endpoint, method and status code",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"endpoint", "method", "code"},
+		),
+		InFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "http_client_in_flight_requests",
+			Help:      "Number of in-flight Linode API (HTTP) requests",
+		}),
+		Bytes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "http_client_response_bytes_total",
+				Help:      "Number of bytes received in Linode API (HTTP) response bodies by endpoint",
+			},
+			[]string{"endpoint"},
+		),
+	}
+
+	opt := promhttp.WithLabelFromCtx("endpoint", func(ctx context.Context) string {
+		endpoint, _ := ctx.Value(endpointKey{}).(string)
+		return endpoint
+	})
+	i.next = promhttp.InstrumentRoundTripperInFlight(i.InFlight,
+		promhttp.InstrumentRoundTripperCounter(i.Requests,
+			promhttp.InstrumentRoundTripperDuration(i.Duration,
+				promhttp.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					resp, err := next.RoundTrip(req)
+					if err == nil && resp.Body != nil {
+						endpoint, _ := req.Context().Value(endpointKey{}).(string)
+						resp.Body = &countingBody{
+							ReadCloser: resp.Body,
+							counter:    i.Bytes.WithLabelValues(endpoint),
+						}
+					}
+					return resp, err
+				}),
+				opt,
+			),
+			opt,
+		),
+	)
+	return i
+}
+
+// RoundTrip implements http.RoundTripper
+func (i *Instrumented) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := context.WithValue(req.Context(), endpointKey{}, Endpoint(req.URL.EscapedPath()))
+	return i.next.RoundTrip(req.WithContext(ctx))
+}
+
+// Collect implements Collector interface and is called by Prometheus to collect metrics
+func (i *Instrumented) Collect(ch chan<- prometheus.Metric) {
+	log.Println("[Instrumented:Collect] Entered")
+	i.Requests.Collect(ch)
+	i.Duration.Collect(ch)
+	i.InFlight.Collect(ch)
+	i.Bytes.Collect(ch)
+	log.Println("[Instrumented:Collect] Completes")
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (i *Instrumented) Describe(ch chan<- *prometheus.Desc) {
+	log.Println("[Instrumented:Describe] Entered")
+	i.Requests.Describe(ch)
+	i.Duration.Describe(ch)
+	i.InFlight.Describe(ch)
+	i.Bytes.Describe(ch)
+	log.Println("[Instrumented:Describe] Completes")
+}
+
+// Endpoint normalises a Linode API path into an endpoint template to bound cardinality
+// e.g. /v4/linode/instances/123/stats becomes /linode/instances/{id}/stats
+// path should be escaped (see url.URL.EscapedPath) so that identifiers containing '/' are a single segment
+// An unknown segment (and any segments that follow it) is collapsed into {id}; a query string (if any) is dropped
+func Endpoint(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	path = strings.Trim(version.ReplaceAllString(path, ""), "/")
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i := 0; i < len(segments); i++ {
+		if isNumeric(segments[i]) {
+			segments[i] = "{id}"
+			continue
+		}
+		if placeholders, ok := identifiers[segments[i]]; ok {
+			for j, placeholder := range placeholders {
+				if i+1+j < len(segments) {
+					segments[i+1+j] = placeholder
+				}
+			}
+			i += len(placeholders)
+			continue
+		}
+		if !resources[segments[i]] {
+			segments = append(segments[:i], "{id}")
+			break
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// isNumeric returns true if s is a non-empty string of digits
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// countingBody counts the bytes read from a response body
+type countingBody struct {
+	io.ReadCloser
+	counter prometheus.Counter
+}
+
+// Read implements io.Reader
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.counter.Add(float64(n))
+	return n, err
+}
@@ -0,0 +1,105 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestEndpoint(t *testing.T) {
+	for _, test := range []struct {
+		path string
+		want string
+	}{
+		{path: "/v4/account", want: "/account"},
+		{path: "/v4/linode/instances", want: "/linode/instances"},
+		{path: "/v4/linode/instances/123", want: "/linode/instances/{id}"},
+		{path: "/v4/linode/instances/456/", want: "/linode/instances/{id}"},
+		{path: "/v4/linode/instances/123/stats", want: "/linode/instances/{id}/stats"},
+		{path: "/v4beta/lke/clusters/3001/pools/4001", want: "/lke/clusters/{id}/pools/{id}"},
+		{path: "/v4/nodebalancers/2001/configs/9001/nodes", want: "/nodebalancers/{id}/configs/{id}/nodes"},
+		{path: "/v4/nodebalancers/2001/stats", want: "/nodebalancers/{id}/stats"},
+		// Collections whose members are identified by names
+		{path: "/v4/object-storage/buckets", want: "/object-storage/buckets"},
+		{path: "/v4/object-storage/buckets/us-east", want: "/object-storage/buckets/{region}"},
+		{path: "/v4/object-storage/buckets/us-east/assets", want: "/object-storage/buckets/{region}/{label}"},
+		{path: "/v4/object-storage/buckets/us-east-1/backups/object-list", want: "/object-storage/buckets/{region}/{label}/object-list"},
+		{path: "/v4/object-storage/quotas/obj-objects-us-ord-1.linodeobjects.com", want: "/object-storage/quotas/{id}"},
+		{path: "/v4/tags/env:prod", want: "/tags/{label}"},
+		{path: "/v4/account/users/alice", want: "/account/users/{username}"},
+		{path: "/v4/account/users/alice/grants", want: "/account/users/{username}/grants"},
+		{path: "/v4/lke/clusters/3001/nodes/3001-5c3d9b7a0000", want: "/lke/clusters/{id}/nodes/{id}"},
+		{path: "/v4/object-storage/buckets/us-east-1/backups/access", want: "/object-storage/buckets/{region}/{label}/access"},
+		{path: "/v4/object-storage/quotas/obj-buckets-us-ord-1.linodeobjects.com/usage", want: "/object-storage/quotas/{id}/usage"},
+		// Identifiers containing '/' are escaped and are a single segment
+		{path: "/v4/tags/a%2F..%2F..%2Ftmp%2Fevil", want: "/tags/{label}"},
+		{path: "/v4/tags/team%2Fweb/extra", want: "/tags/{label}/{id}"},
+		// Unknown (non-numeric) segments and any that follow them are collapsed
+		{path: "/v4/linode/instances/123/unknown", want: "/linode/instances/{id}/{id}"},
+		{path: "/v4/linode/instances/123/web-1/stats/2024/05", want: "/linode/instances/{id}/{id}"},
+		{path: "/v4/images/linode/debian12", want: "/images/linode/{id}"},
+		{path: "/v4/unknown/123", want: "/{id}"},
+		// Query strings do not create endpoints
+		{path: "/v4/linode/instances?page=2&page_size=500", want: "/linode/instances"},
+		{path: "/v4/linode/instances/123/stats?page=2", want: "/linode/instances/{id}/stats"},
+		// Paths without an API version
+		{path: "/linode/instances/123", want: "/linode/instances/{id}"},
+		{path: "/", want: "/"},
+		{path: "/v4", want: "/"},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			if got := Endpoint(test.path); got != test.want {
+				t.Errorf("got %q; want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInstrumented(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/999") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	i := NewInstrumented(nil)
+	client := &http.Client{Transport: i}
+	for _, path := range []string{
+		"/v4/linode/instances?page=1",
+		"/v4/linode/instances?page=2",
+		"/v4/linode/instances/123",
+		"/v4/linode/instances/456",
+		"/v4/linode/instances/999",
+		// Tags of the form key/value are escaped by linodego (url.PathEscape)
+		"/v4/tags/team%2Fweb",
+		"/v4/tags/a%2F..%2F..%2Ftmp%2Fevil",
+	} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	// Paginated requests and requests for each Linode (or tag) collapse to templated endpoints
+	want := `
+# HELP linode_exporter_http_client_requests_total Number of Linode API (HTTP) requests by endpoint, method and status code
+# TYPE linode_exporter_http_client_requests_total counter
+linode_exporter_http_client_requests_total{code="200",endpoint="/linode/instances",method="get"} 2
+linode_exporter_http_client_requests_total{code="200",endpoint="/linode/instances/{id}",method="get"} 2
+linode_exporter_http_client_requests_total{code="404",endpoint="/linode/instances/{id}",method="get"} 1
+linode_exporter_http_client_requests_total{code="200",endpoint="/tags/{label}",method="get"} 2
+`
+	if err := testutil.CollectAndCompare(i.Requests, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.ToFloat64(i.InFlight); got != 0 {
+		t.Errorf("got %v in-flight requests; want 0", got)
+	}
+}
@@ -0,0 +1,208 @@
+package transport
+
+import (
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "linode"
+	subsystem = "exporter"
+
+	headerLimit      = "X-RateLimit-Limit"
+	headerRemaining  = "X-RateLimit-Remaining"
+	headerReset      = "X-RateLimit-Reset"
+	headerRetryAfter = "Retry-After"
+
+	baseBackoff = 500 * time.Millisecond
+	maxBackoff  = 30 * time.Second
+)
+
+// RateLimit is an http.RoundTripper that records the Linode API's rate-limit headers
+// and retries 429 and 5xx responses with jittered exponential backoff (honouring Retry-After)
+// RateLimit is also a Collector that reports the rate-limit headers and retries
+type RateLimit struct {
+	next         http.RoundTripper
+	maxRetries   int
+	minRemaining int
+
+	mu        sync.Mutex
+	known     bool
+	remaining int
+
+	Limit     prometheus.Gauge
+	Remaining prometheus.Gauge
+	Reset     prometheus.Gauge
+	Retries   *prometheus.CounterVec
+}
+
+// NewRateLimit creates a RateLimit wrapping next
+// The budget is Low when fewer than minRemaining requests remain in the current rate-limit window
+func NewRateLimit(next http.RoundTripper, maxRetries, minRemaining int) *RateLimit {
+	log.Println("[NewRateLimit] Entered")
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &RateLimit{
+		next:         next,
+		maxRetries:   maxRetries,
+		minRemaining: minRemaining,
+
+		Limit: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "api_ratelimit_limit",
+			Help:      "Linode API rate-limit: maximum number of requests in the current window (X-RateLimit-Limit)",
+		}),
+		Remaining: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "api_ratelimit_remaining",
+			Help:      "Linode API rate-limit: number of requests remaining in the current window (X-RateLimit-Remaining)",
+		}),
+		Reset: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "api_ratelimit_reset_timestamp_seconds",
+			Help:      "Linode API rate-limit: time at which the current window resets (X-RateLimit-Reset)",
+		}),
+		Retries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "api_retries_total",
+				Help:      "Number of Linode API requests retried by response status code",
+			},
+			[]string{"code"},
+		),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RateLimit) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		t.record(resp.Header)
+
+		if !Retryable(resp.StatusCode) || attempt >= t.maxRetries || !rewindable(req) {
+			return resp, nil
+		}
+
+		wait := Backoff(attempt)
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get(headerRetryAfter)); ok {
+			wait = min(retryAfter, maxBackoff)
+		}
+		log.Printf("[RateLimit:RoundTrip] %s %s returned %d; retrying in %s", req.Method, req.URL.Path, resp.StatusCode, wait)
+		t.Retries.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// Low returns true if the rate-limit budget is known to be low
+func (t *RateLimit) Low() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.known && t.remaining < t.minRemaining
+}
+
+// Collect implements Collector interface and is called by Prometheus to collect metrics
+func (t *RateLimit) Collect(ch chan<- prometheus.Metric) {
+	log.Println("[RateLimit:Collect] Entered")
+	// Rate-limit headers are only reported once they have been received
+	t.mu.Lock()
+	known := t.known
+	t.mu.Unlock()
+	if known {
+		t.Limit.Collect(ch)
+		t.Remaining.Collect(ch)
+		t.Reset.Collect(ch)
+	}
+	t.Retries.Collect(ch)
+	log.Println("[RateLimit:Collect] Completes")
+}
+
+// Describe implements Collector interface and is called by Prometheus to describe metrics
+func (t *RateLimit) Describe(ch chan<- *prometheus.Desc) {
+	log.Println("[RateLimit:Describe] Entered")
+	t.Limit.Describe(ch)
+	t.Remaining.Describe(ch)
+	t.Reset.Describe(ch)
+	t.Retries.Describe(ch)
+	log.Println("[RateLimit:Describe] Completes")
+}
+
+// record updates the rate-limit metrics (and budget) from the response headers, when present
+func (t *RateLimit) record(header http.Header) {
+	if v, err := strconv.Atoi(header.Get(headerLimit)); err == nil {
+		t.Limit.Set(float64(v))
+	}
+	if v, err := strconv.Atoi(header.Get(headerRemaining)); err == nil {
+		t.Remaining.Set(float64(v))
+		t.mu.Lock()
+		t.known = true
+		t.remaining = v
+		t.mu.Unlock()
+	}
+	if v, err := strconv.ParseInt(header.Get(headerReset), 10, 64); err == nil {
+		t.Reset.Set(float64(v))
+	}
+}
+
+// Retryable returns true for 429 (Too Many Requests) and 5xx responses
+func Retryable(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// rewindable returns true if the request's body (if any) can be sent again
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// Backoff returns a "full jitter" exponential backoff for the (zero-based) attempt
+func Backoff(attempt int) time.Duration {
+	d := maxBackoff
+	if attempt < 6 {
+		d = min(baseBackoff<<attempt, maxBackoff)
+	}
+	return rand.N(d) + 1
+}
+
+// parseRetryAfter parses a Retry-After header of either delay-seconds or HTTP-date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,312 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// responses is an http.RoundTripper that returns its responses in turn and records the requests' bodies
+type responses struct {
+	responses []*http.Response
+	bodies    []string
+}
+
+func (r *responses) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = string(b)
+	}
+	r.bodies = append(r.bodies, body)
+	if len(r.bodies) > len(r.responses) {
+		return nil, errors.New("unexpected request")
+	}
+	return r.responses[len(r.bodies)-1], nil
+}
+
+// response returns a response with the status code and headers (name, value pairs)
+func response(code int, headers ...string) *http.Response {
+	resp := &http.Response{
+		StatusCode: code,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		resp.Header.Set(headers[i], headers[i+1])
+	}
+	return resp
+}
+
+func TestRateLimitRetryAfter(t *testing.T) {
+	next := &responses{
+		responses: []*http.Response{
+			response(http.StatusTooManyRequests, headerRetryAfter, "1"),
+			response(http.StatusOK),
+		},
+	}
+	rl := NewRateLimit(next, 3, 0)
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://api.linode.com/v4/linode/instances", nil)
+	start := time.Now()
+	resp, err := rl.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got %d; want %d", resp.StatusCode, http.StatusOK)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s; want Retry-After (1s)", elapsed)
+	}
+	if len(next.bodies) != 2 {
+		t.Errorf("got %d requests; want 2", len(next.bodies))
+	}
+	if got := testutil.ToFloat64(rl.Retries.WithLabelValues("429")); got != 1 {
+		t.Errorf("got %v retries; want 1", got)
+	}
+}
+
+func TestRateLimitRetryServerError(t *testing.T) {
+	next := &responses{
+		responses: []*http.Response{
+			response(http.StatusServiceUnavailable),
+			response(http.StatusBadGateway),
+			response(http.StatusOK),
+		},
+	}
+	rl := NewRateLimit(next, 3, 0)
+
+	// The body is sent again with each retry
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "https://api.linode.com/v4/linode/instances", strings.NewReader(`{"label":"web-1"}`))
+	resp, err := rl.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got %d; want %d", resp.StatusCode, http.StatusOK)
+	}
+	for i, body := range next.bodies {
+		if body != `{"label":"web-1"}` {
+			t.Errorf("got request %d body %q; want the original body", i, body)
+		}
+	}
+	want := `
+# HELP linode_exporter_api_retries_total Number of Linode API requests retried by response status code
+# TYPE linode_exporter_api_retries_total counter
+linode_exporter_api_retries_total{code="502"} 1
+linode_exporter_api_retries_total{code="503"} 1
+`
+	if err := testutil.CollectAndCompare(rl.Retries, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRateLimitNotRetried(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		maxRetries int
+		responses  []*http.Response
+		body       io.Reader
+		want       int
+	}{
+		{
+			name:       "client error",
+			maxRetries: 3,
+			responses:  []*http.Response{response(http.StatusNotFound)},
+			want:       http.StatusNotFound,
+		},
+		{
+			name:       "retries exhausted",
+			maxRetries: 1,
+			responses: []*http.Response{
+				response(http.StatusTooManyRequests, headerRetryAfter, "0"),
+				response(http.StatusTooManyRequests, headerRetryAfter, "0"),
+			},
+			want: http.StatusTooManyRequests,
+		},
+		{
+			// The body of a request without GetBody cannot be sent again
+			name:       "not rewindable",
+			maxRetries: 3,
+			responses:  []*http.Response{response(http.StatusServiceUnavailable)},
+			body:       io.MultiReader(strings.NewReader("{}")),
+			want:       http.StatusServiceUnavailable,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			next := &responses{responses: test.responses}
+			rl := NewRateLimit(next, test.maxRetries, 0)
+
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "https://api.linode.com/v4/linode/instances", test.body)
+			resp, err := rl.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != test.want {
+				t.Errorf("got %d; want %d", resp.StatusCode, test.want)
+			}
+			if len(next.bodies) != len(test.responses) {
+				t.Errorf("got %d requests; want %d", len(next.bodies), len(test.responses))
+			}
+		})
+	}
+}
+
+func TestRateLimitContextDone(t *testing.T) {
+	next := &responses{
+		responses: []*http.Response{
+			response(http.StatusTooManyRequests, headerRetryAfter, "30"),
+		},
+	}
+	rl := NewRateLimit(next, 3, 0)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.linode.com/v4/linode/instances", nil)
+	if _, err := rl.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRateLimitLow(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		remaining string
+		want      bool
+	}{
+		{name: "unknown", remaining: "", want: false},
+		{name: "malformed", remaining: "many", want: false},
+		{name: "below", remaining: "49", want: true},
+		{name: "at", remaining: "50", want: false},
+		{name: "above", remaining: "799", want: false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resp := response(http.StatusOK, headerLimit, "800", headerReset, "1700000000")
+			if test.remaining != "" {
+				resp.Header.Set(headerRemaining, test.remaining)
+			}
+			rl := NewRateLimit(&responses{responses: []*http.Response{resp}}, 0, 50)
+			if rl.Low() {
+				t.Error("got low before any response")
+			}
+
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://api.linode.com/v4/linode/instances", nil)
+			if _, err := rl.RoundTrip(req); err != nil {
+				t.Fatal(err)
+			}
+			if got := rl.Low(); got != test.want {
+				t.Errorf("got %t; want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitCollect(t *testing.T) {
+	rl := NewRateLimit(&responses{
+		responses: []*http.Response{
+			response(http.StatusOK, headerLimit, "800", headerRemaining, "799", headerReset, "1700000000"),
+		},
+	}, 0, 0)
+	names := []string{
+		"linode_exporter_api_ratelimit_limit",
+		"linode_exporter_api_ratelimit_remaining",
+		"linode_exporter_api_ratelimit_reset_timestamp_seconds",
+	}
+
+	// Rate-limit headers are not reported until they have been received
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(rl)
+	if got, err := testutil.GatherAndCount(registry, names...); err != nil || got != 0 {
+		t.Errorf("got %d metrics (%v); want 0", got, err)
+	}
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://api.linode.com/v4/linode/instances", nil)
+	if _, err := rl.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	want := `
+# HELP linode_exporter_api_ratelimit_limit Linode API rate-limit: maximum number of requests in the current window (X-RateLimit-Limit)
+# TYPE linode_exporter_api_ratelimit_limit gauge
+linode_exporter_api_ratelimit_limit 800
+# HELP linode_exporter_api_ratelimit_remaining Linode API rate-limit: number of requests remaining in the current window (X-RateLimit-Remaining)
+# TYPE linode_exporter_api_ratelimit_remaining gauge
+linode_exporter_api_ratelimit_remaining 799
+# HELP linode_exporter_api_ratelimit_reset_timestamp_seconds Linode API rate-limit: time at which the current window resets (X-RateLimit-Reset)
+# TYPE linode_exporter_api_ratelimit_reset_timestamp_seconds gauge
+linode_exporter_api_ratelimit_reset_timestamp_seconds 1.7e+09
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want), names...); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	for _, test := range []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{value: "", ok: false},
+		{value: "0", want: 0, ok: true},
+		{value: "5", want: 5 * time.Second, ok: true},
+		{value: "soon", ok: false},
+		// HTTP-dates in the past do not wait
+		{value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, ok: true},
+	} {
+		t.Run(test.value, func(t *testing.T) {
+			got, ok := parseRetryAfter(test.value)
+			if got != test.want || ok != test.ok {
+				t.Errorf("got (%s, %t); want (%s, %t)", got, ok, test.want, test.ok)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	for code, want := range map[int]bool{
+		http.StatusOK:                  false,
+		http.StatusBadRequest:          false,
+		http.StatusNotFound:            false,
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusServiceUnavailable:  true,
+	} {
+		if got := Retryable(code); got != want {
+			t.Errorf("got %t for %d; want %t", got, code, want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt, bound := range []time.Duration{
+		500 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		16 * time.Second,
+		30 * time.Second,
+		30 * time.Second,
+	} {
+		for range 100 {
+			if got := Backoff(attempt); got <= 0 || got > bound {
+				t.Fatalf("got %s for attempt %d; want (0, %s]", got, attempt, bound)
+			}
+		}
+	}
+	// Large attempts do not overflow
+	if got := Backoff(100); got <= 0 || got > maxBackoff {
+		t.Errorf("got %s for attempt 100; want (0, %s]", got, maxBackoff)
+	}
+}
@@ -0,0 +1,232 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	headerFilter = "X-Filter"
+
+	redactedEmail = "redacted@example.com"
+)
+
+var (
+	// email matches email addresses in response bodies
+	email = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+	// recordedHeaders are the response headers that are recorded (and replayed)
+	recordedHeaders = []string{
+		"Content-Type",
+		headerLimit,
+		headerRemaining,
+		headerReset,
+		headerRetryAfter,
+	}
+)
+
+// Recording is a (sanitised) Linode API request and its response
+type Recording struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a (sanitised) Linode API request
+// Path is escaped (see url.URL.EscapedPath) so that identifiers containing '/' (e.g. tags) are a single segment
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Filter string `json:"filter,omitempty"`
+}
+
+// RecordedResponse is a (sanitised) Linode API response
+type RecordedResponse struct {
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   json.RawMessage   `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that records Linode API requests and responses to a directory
+// Recordings are sanitised: the token (Authorization header) is not recorded and email addresses are redacted
+type Recorder struct {
+	next http.RoundTripper
+	dir  string
+}
+
+// NewRecorder creates a Recorder wrapping next that records to dir
+func NewRecorder(next http.RoundTripper, dir string) *Recorder {
+	log.Printf("[NewRecorder] Recording to %s", dir)
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{
+		next: next,
+		dir:  dir,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+// Failure to record is logged and does not fail the request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := r.record(req, resp, body); err != nil {
+		log.Printf("[Recorder:RoundTrip] unable to record %s %s: %v", req.Method, req.URL.Path, err)
+	}
+	return resp, nil
+}
+
+func (r *Recorder) record(req *http.Request, resp *http.Response, body []byte) error {
+	recording := Recording{
+		Request: newRecordedRequest(req),
+		Response: RecordedResponse{
+			Status: resp.StatusCode,
+			Header: map[string]string{},
+		},
+	}
+	for _, key := range recordedHeaders {
+		if v := resp.Header.Get(key); v != "" {
+			recording.Response.Header[key] = v
+		}
+	}
+	body = email.ReplaceAll(body, []byte(redactedEmail))
+	if json.Valid(body) {
+		recording.Response.Body = body
+	} else if len(body) > 0 {
+		// Bodies are expected to be JSON; others are recorded as JSON strings
+		recording.Response.Body, _ = json.Marshal(string(body))
+	}
+
+	path, err := recording.Request.path(r.dir)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// Replayer is an http.RoundTripper that serves Linode API responses from a Recorder's recordings
+// Requests that were not recorded receive a 404 (Not Found) response
+type Replayer struct {
+	dir string
+}
+
+// NewReplayer creates a Replayer that replays recordings from dir
+func NewReplayer(dir string) *Replayer {
+	log.Printf("[NewReplayer] Replaying from %s", dir)
+	return &Replayer{
+		dir: dir,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	path, err := newRecordedRequest(req).path(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("[Replayer:RoundTrip] %s %s was not recorded", req.Method, req.URL.Path)
+		return newResponse(req, http.StatusNotFound, nil, fmt.Appendf(nil, `{"errors":[{"reason":%q}]}`, "Not recorded")), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var recording Recording
+	if err := json.Unmarshal(b, &recording); err != nil {
+		return nil, fmt.Errorf("unable to parse recording of %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	return newResponse(req, recording.Response.Status, recording.Response.Header, recording.Response.Body), nil
+}
+
+// newRecordedRequest returns a (sanitised) record of the request
+// The Authorization header (token) is not recorded
+func newRecordedRequest(req *http.Request) RecordedRequest {
+	return RecordedRequest{
+		Method: req.Method,
+		Path:   version.ReplaceAllString(req.URL.EscapedPath(), ""),
+		Query:  req.URL.RawQuery,
+		Filter: req.Header.Get(headerFilter),
+	}
+}
+
+// filename returns the recording's (relative) filename
+// e.g. GET /linode/instances is recorded as linode/instances.json
+// Other methods, queries (e.g. page=2) and filters are distinguished by a suffix of their hash
+func (r RecordedRequest) filename() string {
+	name := strings.Trim(r.Path, "/")
+	if name == "" {
+		name = "index"
+	}
+	// ':' is not permitted in filenames on some platforms (and in Go modules), e.g. tags of the form key:value
+	name = strings.ReplaceAll(name, ":", "%3A")
+	if r.Method != http.MethodGet || r.Query != "" || r.Filter != "" {
+		h := sha256.Sum256([]byte(r.Method + "\n" + r.Query + "\n" + r.Filter))
+		name += "@" + hex.EncodeToString(h[:])[:12]
+	}
+	return filepath.FromSlash(name) + ".json"
+}
+
+// path returns the recording's path in dir
+// Recordings are confined to dir; requests whose paths would be recorded elsewhere (e.g. containing ..) are an error
+func (r RecordedRequest) path(dir string) (string, error) {
+	path := filepath.Join(dir, r.filename())
+	if rel, err := filepath.Rel(dir, path); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%s %s would be recorded outside %s", r.Method, r.Path, dir)
+	}
+	return path, nil
+}
+
+func newResponse(req *http.Request, status int, header map[string]string, body []byte) *http.Response {
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	resp.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
@@ -0,0 +1,254 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// exchange is a Linode API request and its expected response
+type exchange struct {
+	method string
+	path   string
+	filter string
+	status int
+	body   string
+}
+
+func (e exchange) request(t *testing.T, client *http.Client, base string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), e.method, base+e.path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret-token")
+	if e.filter != "" {
+		req.Header.Set(headerFilter, e.filter)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestRecordReplay(t *testing.T) {
+	exchanges := []exchange{
+		{method: http.MethodGet, path: "/v4/account", status: http.StatusOK, body: `{"email":"alice@example.org","euuid":"E1"}`},
+		{method: http.MethodGet, path: "/v4/linode/instances", status: http.StatusOK, body: `{"data":[],"page":1}`},
+		// Queries, filters and methods of the same path are recorded separately
+		{method: http.MethodGet, path: "/v4/linode/instances?page=2", status: http.StatusOK, body: `{"data":[],"page":2}`},
+		{method: http.MethodGet, path: "/v4/linode/instances", filter: `{"region":"us-east"}`, status: http.StatusOK, body: `{"data":[],"filter":"region"}`},
+		{method: http.MethodGet, path: "/v4/linode/instances", filter: `{"tags":"web"}`, status: http.StatusOK, body: `{"data":[],"filter":"tags"}`},
+		{method: http.MethodPost, path: "/v4/linode/instances", status: http.StatusBadRequest, body: `{"errors":[{"reason":"Read only"}]}`},
+		{method: http.MethodGet, path: "/v4/tags/env:prod", status: http.StatusOK, body: `{"data":[]}`},
+		{method: http.MethodGet, path: "/v4/support/tickets/1", status: http.StatusOK, body: `{"opened_by":"bob","description":"Contact bob.smith+ops@sub.example.com"}`},
+	}
+	responses := map[string]exchange{}
+	for _, e := range exchanges {
+		responses[e.method+" "+e.path+" "+e.filter] = e
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if r.URL.RawQuery != "" {
+			path += "?" + r.URL.RawQuery
+		}
+		e, ok := responses[r.Method+" "+path+" "+r.Header.Get(headerFilter)]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(headerRemaining, "799")
+		w.Header().Set("Set-Cookie", "session=secret-cookie")
+		w.WriteHeader(e.status)
+		fmt.Fprint(w, e.body)
+	}))
+
+	dir := t.TempDir()
+	recorder := &http.Client{Transport: NewRecorder(nil, dir)}
+	for _, e := range exchanges {
+		resp := e.request(t, recorder, server.URL)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		// Responses are passed through unchanged
+		if resp.StatusCode != e.status || string(body) != e.body {
+			t.Errorf("%s %s: got (%d, %s); want (%d, %s)", e.method, e.path, resp.StatusCode, body, e.status, e.body)
+		}
+	}
+	server.Close()
+
+	// Each request is recorded to its own file; none contain credentials or email addresses
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		files = append(files, path)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		recording := string(b)
+		for _, secret := range []string{"Authorization", "secret-token", "Set-Cookie", "secret-cookie"} {
+			if strings.Contains(recording, secret) {
+				t.Errorf("%s contains %q", path, secret)
+			}
+		}
+		for _, address := range email.FindAllString(recording, -1) {
+			if address != redactedEmail {
+				t.Errorf("%s contains email address %q", path, address)
+			}
+		}
+		if strings.Contains(filepath.Base(path), ":") {
+			t.Errorf("%s contains ':'", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(exchanges) {
+		t.Errorf("got %d recordings; want %d (one per request)\n%s", len(files), len(exchanges), strings.Join(files, "\n"))
+	}
+
+	// The server is closed so the responses are replayed offline
+	replayer := &http.Client{Transport: NewReplayer(dir)}
+	for _, e := range exchanges {
+		resp := e.request(t, replayer, "https://api.linode.com")
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		// Recordings are indented
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, body); err != nil {
+			t.Fatal(err)
+		}
+		want := email.ReplaceAllString(e.body, redactedEmail)
+		if resp.StatusCode != e.status || compact.String() != want {
+			t.Errorf("%s %s %s: replayed (%d, %s); want (%d, %s)", e.method, e.path, e.filter, resp.StatusCode, body, e.status, want)
+		}
+		if got := resp.Header.Get(headerRemaining); got != "799" {
+			t.Errorf("%s %s: replayed %s %q; want 799", e.method, e.path, headerRemaining, got)
+		}
+	}
+
+	// Requests that were not recorded are not found
+	resp := exchange{method: http.MethodGet, path: "/v4/linode/instances?page=3"}.request(t, replayer, "https://api.linode.com")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("got %d for a request that was not recorded; want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestRecordedRequestFilename(t *testing.T) {
+	for _, test := range []struct {
+		request RecordedRequest
+		want    string
+	}{
+		{request: RecordedRequest{Method: http.MethodGet, Path: "/linode/instances"}, want: "linode/instances.json"},
+		{request: RecordedRequest{Method: http.MethodGet, Path: "/linode/instances/123/stats"}, want: "linode/instances/123/stats.json"},
+		{request: RecordedRequest{Method: http.MethodGet, Path: "/tags/env:prod"}, want: "tags/env%3Aprod.json"},
+		{request: RecordedRequest{Method: http.MethodGet, Path: "/"}, want: "index.json"},
+	} {
+		t.Run(test.want, func(t *testing.T) {
+			if got := test.request.filename(); got != filepath.FromSlash(test.want) {
+				t.Errorf("got %q; want %q", got, test.want)
+			}
+		})
+	}
+
+	// Requests that differ only by method, query or filter have distinct filenames
+	seen := map[string]RecordedRequest{}
+	for _, r := range []RecordedRequest{
+		{Method: http.MethodGet, Path: "/linode/instances"},
+		{Method: http.MethodPost, Path: "/linode/instances"},
+		{Method: http.MethodDelete, Path: "/linode/instances"},
+		{Method: http.MethodGet, Path: "/linode/instances", Query: "page=2"},
+		{Method: http.MethodGet, Path: "/linode/instances", Query: "page=3"},
+		{Method: http.MethodPost, Path: "/linode/instances", Query: "page=2"},
+		{Method: http.MethodGet, Path: "/linode/instances", Filter: `{"region":"us-east"}`},
+		{Method: http.MethodGet, Path: "/linode/instances", Query: "page=2", Filter: `{"region":"us-east"}`},
+	} {
+		name := r.filename()
+		if other, ok := seen[name]; ok {
+			t.Errorf("%+v and %+v are both recorded as %s", r, other, name)
+		}
+		seen[name] = r
+	}
+}
+
+func TestRecordReplayHostilePath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":[]}`)
+	}))
+	defer server.Close()
+
+	// Recordings are made to (and replayed from) a subdirectory so that files outside it may be detected
+	base := t.TempDir()
+	dir := filepath.Join(base, "recordings")
+	if err := os.WriteFile(filepath.Join(base, "evil.json"), []byte(`{"response":{"status":200,"body":{"outside":true}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tag label of a/../../../evil is escaped by linodego (url.PathEscape) and is recorded as a single segment
+	escaped := exchange{method: http.MethodGet, path: "/v4/tags/a%2F..%2F..%2F..%2Fevil", status: http.StatusOK, body: `{"data":[]}`}
+	// Unescaped paths that would be recorded outside dir are passed through but are not recorded (or replayed)
+	unescaped := exchange{method: http.MethodGet, path: "/v4/tags/a/../../../evil", status: http.StatusOK, body: `{"data":[]}`}
+
+	recorder := &http.Client{Transport: NewRecorder(nil, dir)}
+	for _, e := range []exchange{escaped, unescaped} {
+		resp := e.request(t, recorder, server.URL)
+		resp.Body.Close()
+		if resp.StatusCode != e.status {
+			t.Errorf("%s: got %d; want %d", e.path, resp.StatusCode, e.status)
+		}
+	}
+
+	// Files outside dir are not overwritten (or created)
+	if b, err := os.ReadFile(filepath.Join(base, "evil.json")); err != nil || !strings.Contains(string(b), "outside") {
+		t.Errorf("got %s (%v); want the file outside %s unchanged", b, err, dir)
+	}
+	err := filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch rel, _ := filepath.Rel(base, path); rel {
+		case "evil.json", filepath.Join("recordings", "tags", "a%2F..%2F..%2F..%2Fevil.json"):
+		default:
+			t.Errorf("got recording %s; want recordings only of the escaped path within %s", rel, dir)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayer := &http.Client{Transport: NewReplayer(dir)}
+	resp := escaped.request(t, replayer, "https://api.linode.com")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || strings.Contains(string(body), "outside") {
+		t.Errorf("%s: replayed (%d, %s); want the recording", escaped.path, resp.StatusCode, body)
+	}
+
+	// Files outside dir are not replayed
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://api.linode.com"+unescaped.path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := replayer.Do(req); err == nil {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		t.Errorf("%s: replayed (%d, %s); want an error", unescaped.path, resp.StatusCode, body)
+	}
+}